@@ -2,10 +2,12 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"github.com/alecthomas/kong"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/build"
+	"github.com/bugsnag/bugsnag-cli/pkg/inspect"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/options"
 	"github.com/bugsnag/bugsnag-cli/pkg/upload"
@@ -23,25 +25,40 @@ func main() {
 		os.Args = append(os.Args, "--help")
 	}
 
+	configResolver := options.NewConfigResolver()
+
 	ctx := kong.Parse(&commands,
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact: true,
 		}),
 		kong.Vars{
 			"version": package_version,
-		})
+		},
+		kong.Resolvers(configResolver))
+
+	log.SetShowSecrets(commands.ShowSecrets)
+	log.AddSecret(commands.ApiKey)
+	log.SetFormat(commands.Output)
+
+	reportPath := commands.Upload.Report
+
+	if configResolver.Path() != "" {
+		log.Info("Using configuration from " + configResolver.Path())
+	}
 
 	// Build connection URI
 	endpoint, err := utils.BuildEndpointUrl(commands.UploadAPIRootUrl, commands.Port)
 
 	if err != nil {
-		log.Error("Failed to build upload url: "+err.Error(), 1)
+		exitWithError("Failed to build upload url: "+err.Error(), reportPath)
 	}
 
 	if commands.DryRun {
 		log.Info("Performing dry run - no data will be sent to BugSnag")
 	}
 
+	retryMaxDelay := time.Duration(commands.Upload.RetryMaxDelay) * time.Second
+
 	if commands.FailOnUploadError {
 		log.Warn("The `--fail-on-upload-error` flag is deprecated and will be removed in a future release. All commands now fail if the upload is unsuccessful.")
 	}
@@ -51,7 +68,7 @@ func main() {
 	case "upload all <path>":
 
 		if commands.ApiKey == "" {
-			log.Error("missing api key, please specify using `--api-key`", 1)
+			exitWithError("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable", reportPath)
 		}
 
 		err := upload.All(
@@ -60,13 +77,15 @@ func main() {
 			endpoint,
 			commands.Upload.Timeout,
 			commands.Upload.Retries,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.ApiKey,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload android-aab <path>":
@@ -83,12 +102,39 @@ func main() {
 			endpoint,
 			commands.Upload.Retries,
 			commands.Upload.Timeout,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
+			commands.Upload.Overwrite,
+			commands.DryRun,
+		)
+
+		if err != nil {
+			exitWithError(err.Error(), reportPath)
+		}
+
+	case "upload android-apk <path>":
+
+		err := upload.ProcessAndroidApk(
+			commands.ApiKey,
+			commands.Upload.AndroidApk.ApplicationId,
+			commands.Upload.AndroidApk.BuildUuid,
+			commands.Upload.AndroidApk.NoBuildUuid,
+			string(commands.Upload.AndroidApk.MappingFile),
+			commands.Upload.AndroidApk.Path,
+			commands.Upload.AndroidApk.ProjectRoot,
+			commands.Upload.AndroidApk.VersionCode,
+			commands.Upload.AndroidApk.VersionName,
+			endpoint,
+			commands.Upload.Retries,
+			commands.Upload.Timeout,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload android-ndk <path>", "upload android-ndk":
@@ -96,22 +142,26 @@ func main() {
 		err := upload.ProcessAndroidNDK(
 			commands.ApiKey,
 			commands.Upload.AndroidNdk.ApplicationId,
-			commands.Upload.AndroidNdk.AndroidNdkRoot,
 			commands.Upload.AndroidNdk.AppManifest,
 			commands.Upload.AndroidNdk.Path,
 			commands.Upload.AndroidNdk.ProjectRoot,
 			commands.Upload.AndroidNdk.Variant,
+			commands.Upload.AndroidNdk.AllVariants,
 			commands.Upload.AndroidNdk.VersionCode,
 			commands.Upload.AndroidNdk.VersionName,
+			string(commands.Upload.AndroidNdk.VerifyAgainst),
+			commands.Upload.AndroidNdk.VerifyWarnOnly,
 			endpoint,
 			commands.Upload.Retries,
 			commands.Upload.Timeout,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload android-proguard <path>", "upload android-proguard":
@@ -125,23 +175,26 @@ func main() {
 			commands.Upload.AndroidProguard.DexFiles,
 			commands.Upload.AndroidProguard.Path,
 			commands.Upload.AndroidProguard.Variant,
+			commands.Upload.AndroidProguard.AllVariants,
 			commands.Upload.AndroidProguard.VersionCode,
 			commands.Upload.AndroidProguard.VersionName,
 			endpoint,
 			commands.Upload.Retries,
 			commands.Upload.Timeout,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload dart <path>":
 
 		if commands.ApiKey == "" {
-			log.Error("missing api key, please specify using `--api-key`", 1)
+			exitWithError("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable", reportPath)
 		}
 
 		err := upload.Dart(
@@ -150,16 +203,20 @@ func main() {
 			commands.Upload.DartSymbol.VersionCode,
 			commands.Upload.DartSymbol.BundleVersion,
 			string(commands.Upload.DartSymbol.IosAppPath),
+			string(commands.Upload.DartSymbol.VerifyAgainst),
+			commands.Upload.DartSymbol.VerifyWarnOnly,
 			endpoint,
 			commands.Upload.Timeout,
 			commands.Upload.Retries,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.ApiKey,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload react-native-android", "upload react-native-android <path>":
@@ -179,12 +236,13 @@ func main() {
 			endpoint,
 			commands.Upload.Timeout,
 			commands.Upload.Retries,
+			retryMaxDelay,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload react-native-ios", "upload react-native-ios <path>":
@@ -197,6 +255,7 @@ func main() {
 			commands.Upload.ReactNativeIos.SourceMap,
 			commands.Upload.ReactNativeIos.Bundle,
 			commands.Upload.ReactNativeIos.Plist,
+			commands.Upload.ReactNativeIos.PlutilFallback,
 			commands.Upload.ReactNativeIos.XcodeProject,
 			commands.Upload.ReactNativeIos.CodeBundleID,
 			commands.Upload.ReactNativeIos.Dev,
@@ -205,39 +264,71 @@ func main() {
 			endpoint,
 			commands.Upload.Timeout,
 			commands.Upload.Retries,
+			retryMaxDelay,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload dsym", "upload dsym <path>":
 
-		err := upload.ProcessDsym(
-			commands.ApiKey,
-			commands.Upload.Dsym.Scheme,
-			string(commands.Upload.Dsym.XcodeProject),
-			string(commands.Upload.Dsym.Plist),
-			commands.Upload.Dsym.ProjectRoot,
-			commands.Upload.Dsym.IgnoreMissingDwarf,
-			commands.Upload.Dsym.IgnoreEmptyDsym,
-			commands.Upload.Dsym.Path,
-			endpoint,
-			commands.Upload.Timeout,
-			commands.Upload.Retries,
-			commands.DryRun,
-		)
+		var err error
+
+		if commands.Upload.Dsym.FromAppStoreConnect {
+			err = upload.ProcessAppStoreConnectDsym(
+				commands.ApiKey,
+				commands.Upload.Dsym.AppStoreConnectKeyId,
+				commands.Upload.Dsym.AppStoreConnectIssuerId,
+				string(commands.Upload.Dsym.AppStoreConnectPrivateKey),
+				commands.Upload.Dsym.AppStoreConnectApiRootUrl,
+				commands.Upload.Dsym.AppId,
+				commands.Upload.Dsym.BuildNumber,
+				commands.Upload.Dsym.ProjectRoot,
+				commands.Upload.Dsym.IgnoreMissingDwarf,
+				commands.Upload.Dsym.IgnoreEmptyDsym,
+				string(commands.Upload.Dsym.VerifyAgainst),
+				commands.Upload.Dsym.VerifyWarnOnly,
+				commands.Upload.Dsym.PlutilFallback,
+				endpoint,
+				commands.Upload.Timeout,
+				commands.Upload.Retries,
+				retryMaxDelay,
+				commands.Upload.Concurrency,
+				commands.DryRun,
+			)
+		} else {
+			err = upload.ProcessDsym(
+				commands.ApiKey,
+				commands.Upload.Dsym.Scheme,
+				string(commands.Upload.Dsym.XcodeProject),
+				string(commands.Upload.Dsym.Plist),
+				commands.Upload.Dsym.PlutilFallback,
+				commands.Upload.Dsym.ProjectRoot,
+				commands.Upload.Dsym.IgnoreMissingDwarf,
+				commands.Upload.Dsym.IgnoreEmptyDsym,
+				commands.Upload.Dsym.Path,
+				string(commands.Upload.Dsym.VerifyAgainst),
+				commands.Upload.Dsym.VerifyWarnOnly,
+				endpoint,
+				commands.Upload.Timeout,
+				commands.Upload.Retries,
+				retryMaxDelay,
+				commands.Upload.Concurrency,
+				commands.DryRun,
+			)
+		}
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "upload unity-android <path>":
 
 		if commands.ApiKey == "" {
-			log.Error("missing api key, please specify using `--api-key`", 1)
+			exitWithError("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable", reportPath)
 		}
 
 		err := upload.ProcessUnityAndroid(
@@ -253,12 +344,14 @@ func main() {
 			endpoint,
 			commands.Upload.Timeout,
 			commands.Upload.Retries,
+			retryMaxDelay,
+			commands.Upload.Concurrency,
 			commands.Upload.Overwrite,
 			commands.DryRun,
 		)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 	case "create-build", "create-build <path>":
@@ -266,27 +359,29 @@ func main() {
 		CreateBuildOptions, err := build.GatherBuildInfo(commands)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 		// Validate Build Info
 		err = CreateBuildOptions.Validate()
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 		// Get Endpoint URL
 		endpoint, err = utils.BuildEndpointUrl(commands.BuildApiRootUrl, commands.Port)
 
 		if err != nil {
-			log.Error("Failed to build upload url: "+err.Error(), 1)
+			exitWithError("Failed to build upload url: "+err.Error(), reportPath)
 		}
 
-		err = build.ProcessCreateBuild(CreateBuildOptions, endpoint, commands.DryRun, commands.CreateBuild.Timeout, commands.CreateBuild.Retries)
+		retryMaxDelay := time.Duration(commands.CreateBuild.RetryMaxDelay) * time.Second
+
+		err = build.ProcessCreateBuild(CreateBuildOptions, endpoint, commands.DryRun, commands.CreateBuild.Timeout, commands.CreateBuild.Retries, retryMaxDelay)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
 		}
 
 		log.Success("Build created")
@@ -295,10 +390,38 @@ func main() {
 		err := build.PrintAndroidBuildId(commands.CreateAndroidBuildId.Path)
 
 		if err != nil {
-			log.Error(err.Error(), 1)
+			exitWithError(err.Error(), reportPath)
+		}
+
+	case "inspect <path>":
+		err := inspect.PrintFileInfo(commands.Inspect.Path)
+
+		if err != nil {
+			exitWithError(err.Error(), reportPath)
 		}
 
 	default:
 		println(ctx.Command())
 	}
+
+	writeReport(reportPath)
+	log.Finish()
+}
+
+// writeReport - Writes the outcome of every file processed to the given path, if a report was requested
+func writeReport(reportPath string) {
+	if reportPath == "" {
+		return
+	}
+
+	err := log.WriteReport(reportPath)
+	if err != nil {
+		log.Warn("Unable to write report to " + reportPath + ": " + err.Error())
+	}
+}
+
+// exitWithError - Writes the report, if one was requested, then displays the error and exits
+func exitWithError(message string, reportPath string) {
+	writeReport(reportPath)
+	log.Error(message, 1)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"os"
 	"path/filepath"
 )
 
@@ -40,6 +41,7 @@ func MergeUploadOptionsFromAabManifest(path string, apiKey string, applicationId
 
 		if aabUploadOptions["apiKey"] == "" && manifestData["apiKey"] != "" {
 			aabUploadOptions["apiKey"] = manifestData["apiKey"]
+			log.AddSecret(manifestData["apiKey"])
 			log.Info("Using " + manifestData["apiKey"] + " as API key from AndroidManifest.xml")
 		}
 
@@ -75,3 +77,26 @@ func MergeUploadOptionsFromAabManifest(path string, apiKey string, applicationId
 	}
 	return aabUploadOptions, nil
 }
+
+// GetAabFeatureModules - Lists the dynamic feature modules in an extracted AAB, which are the modules other than base
+// that have a manifest
+func GetAabFeatureModules(path string) []string {
+	var modules []string
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return modules
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "base" {
+			continue
+		}
+
+		if utils.FileExists(filepath.Join(path, entry.Name(), "manifest", "AndroidManifest.xml")) {
+			modules = append(modules, entry.Name())
+		}
+	}
+
+	return modules
+}
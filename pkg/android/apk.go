@@ -0,0 +1,87 @@
+package android
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// MergeUploadOptionsFromApkManifest - Fills in any options that weren't given from the manifest of an extracted APK,
+// using the signatures of its dex files as the build UUID when the manifest doesn't have one
+func MergeUploadOptionsFromApkManifest(path string, apiKey string, applicationId string, buildUuid string, noBuildUuid bool, versionCode string, versionName string) (map[string]string, error) {
+	apkUploadOptions := make(map[string]string)
+
+	apkUploadOptions["apiKey"] = apiKey
+	apkUploadOptions["applicationId"] = applicationId
+	apkUploadOptions["buildUuid"] = buildUuid
+	apkUploadOptions["versionCode"] = versionCode
+	apkUploadOptions["versionName"] = versionName
+
+	if apiKey != "" && applicationId != "" && buildUuid != "" && versionCode != "" && versionName != "" {
+		return apkUploadOptions, nil
+	}
+
+	apkManifestPath := filepath.Join(path, "AndroidManifest.xml")
+
+	if !utils.FileExists(apkManifestPath) {
+		return apkUploadOptions, fmt.Errorf("AndroidManifest.xml not found in APK file")
+	}
+
+	log.Info("Reading data from AndroidManifest.xml")
+
+	content, err := os.ReadFile(apkManifestPath)
+
+	if err != nil {
+		return apkUploadOptions, err
+	}
+
+	manifestData, err := ReadApkManifest(content)
+
+	if err != nil {
+		return apkUploadOptions, fmt.Errorf("unable to read data from %s: %w", apkManifestPath, err)
+	}
+
+	if apkUploadOptions["apiKey"] == "" && manifestData["apiKey"] != "" {
+		apkUploadOptions["apiKey"] = manifestData["apiKey"]
+		log.AddSecret(manifestData["apiKey"])
+		log.Info("Using " + manifestData["apiKey"] + " as API key from AndroidManifest.xml")
+	}
+
+	if apkUploadOptions["applicationId"] == "" && manifestData["applicationId"] != "" {
+		apkUploadOptions["applicationId"] = manifestData["applicationId"]
+		log.Info("Using " + apkUploadOptions["applicationId"] + " as application ID from AndroidManifest.xml")
+	}
+
+	if noBuildUuid || apkUploadOptions["buildUuid"] == "none" {
+		log.Info("No build ID will be used")
+		apkUploadOptions["buildUuid"] = ""
+	} else if apkUploadOptions["buildUuid"] == "" {
+		apkUploadOptions["buildUuid"] = manifestData["buildUuid"]
+
+		if apkUploadOptions["buildUuid"] != "" {
+			log.Info("Using " + apkUploadOptions["buildUuid"] + " as build ID from AndroidManifest.xml")
+		} else if dexFiles := GetClassesDexFromDir(path); len(dexFiles) > 0 {
+			signature, err := GetAppSignatureFromFiles(dexFiles)
+
+			if err == nil {
+				apkUploadOptions["buildUuid"] = fmt.Sprintf("%x", signature)
+				log.Info("Using " + apkUploadOptions["buildUuid"] + " as build ID from dex signatures")
+			}
+		}
+	}
+
+	if apkUploadOptions["versionCode"] == "" && manifestData["versionCode"] != "" {
+		apkUploadOptions["versionCode"] = manifestData["versionCode"]
+		log.Info("Using " + apkUploadOptions["versionCode"] + " as version code from AndroidManifest.xml")
+	}
+
+	if apkUploadOptions["versionName"] == "" && manifestData["versionName"] != "" {
+		apkUploadOptions["versionName"] = manifestData["versionName"]
+		log.Info("Using " + apkUploadOptions["versionName"] + " as version name from AndroidManifest.xml")
+	}
+
+	return apkUploadOptions, nil
+}
@@ -0,0 +1,319 @@
+package android
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// Chunk types used by Android's binary XML format, from ResourceTypes.h
+const (
+	axmlStringPoolType   = 0x0001
+	axmlXmlType          = 0x0003
+	axmlStartNamespace   = 0x0100
+	axmlEndNamespace     = 0x0101
+	axmlStartElementType = 0x0102
+	axmlEndElementType   = 0x0103
+	axmlResourceMapType  = 0x0180
+)
+
+// Typed value data types, from Res_value in ResourceTypes.h
+const (
+	axmlTypeReference = 0x01
+	axmlTypeAttribute = 0x02
+	axmlTypeString    = 0x03
+	axmlTypeFloat     = 0x04
+	axmlTypeIntDec    = 0x10
+	axmlTypeIntHex    = 0x11
+	axmlTypeIntBool   = 0x12
+)
+
+// axmlStringPoolUtf8 - The string pool flag set when strings are UTF-8 rather than UTF-16 encoded
+const axmlStringPoolUtf8 = 1 << 8
+
+// axmlNoIndex - The string index used for a missing namespace or raw value
+const axmlNoIndex = 0xffffffff
+
+// axmlElement - An element decoded from a binary XML file
+type axmlElement struct {
+	Name       string
+	Attributes []axmlAttribute
+	Children   []*axmlElement
+}
+
+// axmlAttribute - An attribute decoded from a binary XML file. Values that aren't strings are formatted as aapt does
+type axmlAttribute struct {
+	Namespace  string
+	Name       string
+	ResourceId uint32
+	Value      string
+}
+
+// axmlDecoder - The state used while decoding the chunks of a binary XML file
+type axmlDecoder struct {
+	data         []byte
+	strings      []string
+	resourceIds  []uint32
+	root         *axmlElement
+	openElements []*axmlElement
+}
+
+// isAxmlContent - Checks for the header of a binary XML file
+func isAxmlContent(buffer []byte) bool {
+	return len(buffer) >= 8 && binary.LittleEndian.Uint16(buffer[0:2]) == axmlXmlType && binary.LittleEndian.Uint16(buffer[2:4]) == 8
+}
+
+// decodeAxml - Decodes a binary XML file, as found in APKs, into a tree of elements
+func decodeAxml(data []byte) (*axmlElement, error) {
+	if !isAxmlContent(data) {
+		return nil, fmt.Errorf("not a binary XML file")
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[4:8]))
+	if size > len(data) || size < 8 {
+		return nil, fmt.Errorf("binary XML file is truncated")
+	}
+
+	decoder := &axmlDecoder{data: data[:size]}
+
+	for offset := 8; offset+8 <= size; {
+		chunkType := binary.LittleEndian.Uint16(data[offset : offset+2])
+		headerSize := int(binary.LittleEndian.Uint16(data[offset+2 : offset+4]))
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+
+		if chunkSize < 8 || headerSize < 8 || headerSize > chunkSize || offset+chunkSize > size {
+			return nil, fmt.Errorf("invalid binary XML chunk at offset %d", offset)
+		}
+
+		chunk := data[offset : offset+chunkSize]
+		var err error
+
+		switch chunkType {
+		case axmlStringPoolType:
+			err = decoder.readStringPool(chunk, headerSize)
+		case axmlResourceMapType:
+			decoder.readResourceMap(chunk, headerSize)
+		case axmlStartElementType:
+			err = decoder.readStartElement(chunk, headerSize)
+		case axmlEndElementType:
+			if len(decoder.openElements) > 0 {
+				decoder.openElements = decoder.openElements[:len(decoder.openElements)-1]
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		offset += chunkSize
+	}
+
+	if decoder.root == nil {
+		return nil, fmt.Errorf("no elements found in binary XML file")
+	}
+
+	return decoder.root, nil
+}
+
+// readStringPool - Reads the UTF-8 or UTF-16 strings that names and values refer to by index
+func (d *axmlDecoder) readStringPool(chunk []byte, headerSize int) error {
+	if headerSize < 28 {
+		return fmt.Errorf("invalid binary XML string pool")
+	}
+
+	stringCount := int(binary.LittleEndian.Uint32(chunk[8:12]))
+	flags := binary.LittleEndian.Uint32(chunk[16:20])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:24]))
+
+	if stringCount > (len(chunk)-headerSize)/4 || stringsStart > len(chunk) {
+		return fmt.Errorf("invalid binary XML string pool")
+	}
+
+	d.strings = make([]string, stringCount)
+
+	for i := 0; i < stringCount; i++ {
+		offset := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+i*4:headerSize+i*4+4]))
+		if offset >= len(chunk) {
+			return fmt.Errorf("invalid binary XML string offset")
+		}
+
+		var err error
+		if flags&axmlStringPoolUtf8 != 0 {
+			d.strings[i], err = decodeAxmlUtf8String(chunk[offset:])
+		} else {
+			d.strings[i], err = decodeAxmlUtf16String(chunk[offset:])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeAxmlUtf8String - Decodes a string pool entry with its UTF-16 and UTF-8 lengths, each 1 or 2 bytes long
+func decodeAxmlUtf8String(data []byte) (string, error) {
+	offset := 0
+	readLength := func() (int, bool) {
+		if offset >= len(data) {
+			return 0, false
+		}
+		length := int(data[offset])
+		offset++
+		if length&0x80 != 0 {
+			if offset >= len(data) {
+				return 0, false
+			}
+			length = (length&0x7f)<<8 | int(data[offset])
+			offset++
+		}
+		return length, true
+	}
+
+	// The first length is the number of UTF-16 code units, which isn't needed
+	if _, ok := readLength(); !ok {
+		return "", fmt.Errorf("invalid binary XML string")
+	}
+
+	length, ok := readLength()
+	if !ok || offset+length > len(data) {
+		return "", fmt.Errorf("invalid binary XML string")
+	}
+
+	return string(data[offset : offset+length]), nil
+}
+
+// decodeAxmlUtf16String - Decodes a string pool entry with its UTF-16 length, which is 1 or 2 code units long
+func decodeAxmlUtf16String(data []byte) (string, error) {
+	if len(data) < 2 {
+		return "", fmt.Errorf("invalid binary XML string")
+	}
+
+	offset := 2
+	length := int(binary.LittleEndian.Uint16(data[0:2]))
+	if length&0x8000 != 0 {
+		if len(data) < 4 {
+			return "", fmt.Errorf("invalid binary XML string")
+		}
+		length = (length&0x7fff)<<16 | int(binary.LittleEndian.Uint16(data[2:4]))
+		offset = 4
+	}
+
+	if offset+length*2 > len(data) {
+		return "", fmt.Errorf("invalid binary XML string")
+	}
+
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[offset+i*2 : offset+i*2+2])
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+// readResourceMap - Reads the resource IDs of the attribute names, indexed in the same order as the string pool
+func (d *axmlDecoder) readResourceMap(chunk []byte, headerSize int) {
+	for offset := headerSize; offset+4 <= len(chunk); offset += 4 {
+		d.resourceIds = append(d.resourceIds, binary.LittleEndian.Uint32(chunk[offset:offset+4]))
+	}
+}
+
+// readStartElement - Reads an element and its attributes, adding it to the currently open element
+func (d *axmlDecoder) readStartElement(chunk []byte, headerSize int) error {
+	ext := chunk[headerSize:]
+	if len(ext) < 20 {
+		return fmt.Errorf("invalid binary XML element")
+	}
+
+	element := &axmlElement{Name: d.string(binary.LittleEndian.Uint32(ext[4:8]))}
+	attributeStart := int(binary.LittleEndian.Uint16(ext[8:10]))
+	attributeSize := int(binary.LittleEndian.Uint16(ext[10:12]))
+	attributeCount := int(binary.LittleEndian.Uint16(ext[12:14]))
+
+	if attributeSize < 20 || attributeStart+attributeCount*attributeSize > len(ext) {
+		return fmt.Errorf("invalid binary XML attributes in <%s>", element.Name)
+	}
+
+	for i := 0; i < attributeCount; i++ {
+		attribute := ext[attributeStart+i*attributeSize:]
+		nameIndex := binary.LittleEndian.Uint32(attribute[4:8])
+
+		var resourceId uint32
+		if int(nameIndex) < len(d.resourceIds) {
+			resourceId = d.resourceIds[nameIndex]
+		}
+
+		element.Attributes = append(element.Attributes, axmlAttribute{
+			Namespace:  d.string(binary.LittleEndian.Uint32(attribute[0:4])),
+			Name:       d.string(nameIndex),
+			ResourceId: resourceId,
+			Value:      d.attributeValue(attribute),
+		})
+	}
+
+	if len(d.openElements) > 0 {
+		parent := d.openElements[len(d.openElements)-1]
+		parent.Children = append(parent.Children, element)
+	} else if d.root == nil {
+		d.root = element
+	}
+
+	d.openElements = append(d.openElements, element)
+
+	return nil
+}
+
+// attributeValue - Formats the raw or typed value of an attribute
+func (d *axmlDecoder) attributeValue(attribute []byte) string {
+	rawValue := binary.LittleEndian.Uint32(attribute[8:12])
+	if rawValue != axmlNoIndex {
+		return d.string(rawValue)
+	}
+
+	dataType := attribute[15]
+	value := binary.LittleEndian.Uint32(attribute[16:20])
+
+	switch dataType {
+	case axmlTypeString:
+		return d.string(value)
+	case axmlTypeIntDec:
+		return fmt.Sprintf("%d", int32(value))
+	case axmlTypeIntHex:
+		return fmt.Sprintf("0x%x", value)
+	case axmlTypeIntBool:
+		if value != 0 {
+			return "true"
+		}
+		return "false"
+	case axmlTypeFloat:
+		return fmt.Sprintf("%g", math.Float32frombits(value))
+	case axmlTypeReference:
+		return fmt.Sprintf("@0x%08x", value)
+	case axmlTypeAttribute:
+		return fmt.Sprintf("?0x%08x", value)
+	}
+
+	return fmt.Sprintf("0x%08x", value)
+}
+
+// string - Returns the string at an index of the string pool, or an empty string for a missing index
+func (d *axmlDecoder) string(index uint32) string {
+	if int64(index) >= int64(len(d.strings)) {
+		return ""
+	}
+
+	return d.strings[index]
+}
+
+// attribute - Returns the value of the attribute with the given resource ID or, when compiled without one, name
+func (e *axmlElement) attribute(name string, resourceId uint32) string {
+	for _, attribute := range e.Attributes {
+		if (resourceId != 0 && attribute.ResourceId == resourceId) || attribute.Name == name {
+			return attribute.Value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,244 @@
+package android
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// Artifact types written to output-metadata.json by the Android Gradle Plugin
+const (
+	ArtifactMergedManifests   = "MERGED_MANIFESTS"
+	ArtifactPackagedManifests = "PACKAGED_MANIFESTS"
+	ArtifactApk               = "APK"
+)
+
+// manifestDirs - The intermediates directories that the Android Gradle Plugin has written merged manifests to, newest last
+var manifestDirs = []string{"merged_manifests", "packaged_manifests", "merged_manifest"}
+
+// OutputMetadata - The output-metadata.json written by the Android Gradle Plugin alongside the outputs of a variant
+type OutputMetadata struct {
+	Version      int `json:"version"`
+	ArtifactType struct {
+		Type string `json:"type"`
+		Kind string `json:"kind"`
+	} `json:"artifactType"`
+	ApplicationId string                  `json:"applicationId"`
+	VariantName   string                  `json:"variantName"`
+	Elements      []OutputMetadataElement `json:"elements"`
+	Dir           string                  `json:"-"`
+}
+
+// OutputMetadataElement - An output of a variant, such as an APK or manifest, with the version it was built with
+type OutputMetadataElement struct {
+	Type        string `json:"type"`
+	VersionCode int    `json:"versionCode"`
+	VersionName string `json:"versionName"`
+	OutputFile  string `json:"outputFile"`
+}
+
+// ReadOutputMetadata - Reads an output-metadata.json file
+func ReadOutputMetadata(path string) (*OutputMetadata, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata OutputMetadata
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return nil, err
+	}
+
+	metadata.Dir = filepath.Dir(path)
+
+	return &metadata, nil
+}
+
+// FindOutputMetadata - Finds the output-metadata.json files of a variant with the given artifact type in a module's
+// build directory, wherever in intermediates or outputs the Android Gradle Plugin has written them
+func FindOutputMetadata(buildDir string, variant string, artifactType string) []*OutputMetadata {
+	var found []*OutputMetadata
+
+	patterns := []string{
+		filepath.Join(buildDir, "intermediates", "*", "*", "output-metadata.json"),
+		filepath.Join(buildDir, "intermediates", "*", "*", "*", "output-metadata.json"),
+		filepath.Join(buildDir, "outputs", "*", "*", "output-metadata.json"),
+	}
+
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+
+		for _, match := range matches {
+			metadata, err := ReadOutputMetadata(match)
+			if err != nil || metadata.VariantName != variant || metadata.ArtifactType.Type != artifactType {
+				continue
+			}
+
+			found = append(found, metadata)
+		}
+	}
+
+	return found
+}
+
+// FindVariantManifest - Finds the merged AndroidManifest.xml of a variant in a module's build directory, using the
+// output metadata of the build where it exists and the known layouts of the Android Gradle Plugin otherwise
+func FindVariantManifest(buildDir string, variant string) string {
+	for _, artifactType := range []string{ArtifactMergedManifests, ArtifactPackagedManifests} {
+		for _, metadata := range FindOutputMetadata(buildDir, variant, artifactType) {
+			for _, element := range metadata.Elements {
+				manifestPath := filepath.Join(metadata.Dir, element.OutputFile)
+				if element.OutputFile != "" && utils.FileExists(manifestPath) {
+					return manifestPath
+				}
+			}
+		}
+	}
+
+	for _, manifestDir := range manifestDirs {
+		variantPath := filepath.Join(buildDir, "intermediates", manifestDir, variant)
+
+		if utils.FileExists(filepath.Join(variantPath, "AndroidManifest.xml")) {
+			return filepath.Join(variantPath, "AndroidManifest.xml")
+		}
+
+		// Newer versions write the manifest to a directory named after the task, e.g. release/processReleaseMainManifest
+		if matches, _ := filepath.Glob(filepath.Join(variantPath, "*", "AndroidManifest.xml")); len(matches) > 0 {
+			return matches[0]
+		}
+
+		manifestVariants, err := GetVariants(filepath.Join(buildDir, "intermediates", manifestDir), "AndroidManifest.xml")
+		if err != nil {
+			continue
+		}
+
+		for _, manifestVariant := range manifestVariants {
+			if manifestVariant.Name == variant {
+				return filepath.Join(manifestVariant.Path, "AndroidManifest.xml")
+			}
+		}
+	}
+
+	return ""
+}
+
+// GetVariantOutputVersion - Gets the application ID, version code and version name that a variant's APK was built
+// with from its output metadata, for when the variant's manifest can't be found
+func GetVariantOutputVersion(buildDir string, variant string) (applicationId string, versionCode string, versionName string) {
+	for _, metadata := range FindOutputMetadata(buildDir, variant, ArtifactApk) {
+		for _, element := range metadata.Elements {
+			if element.VersionCode != 0 || element.VersionName != "" {
+				if element.VersionCode != 0 {
+					versionCode = strconv.Itoa(element.VersionCode)
+				}
+
+				return metadata.ApplicationId, versionCode, element.VersionName
+			}
+		}
+
+		if metadata.ApplicationId != "" {
+			return metadata.ApplicationId, "", ""
+		}
+	}
+
+	return "", "", ""
+}
+
+// MergeVariantOutputVersion - Fills in the application ID, version code and version name that weren't given from the
+// output metadata of a variant's APK
+func MergeVariantOutputVersion(buildDir string, variant string, applicationId string, versionCode string, versionName string) (string, string, string) {
+	if applicationId != "" && versionCode != "" && versionName != "" {
+		return applicationId, versionCode, versionName
+	}
+
+	outputApplicationId, outputVersionCode, outputVersionName := GetVariantOutputVersion(buildDir, variant)
+
+	if applicationId == "" && outputApplicationId != "" {
+		applicationId = outputApplicationId
+		log.Info("Using " + applicationId + " as application ID from output-metadata.json")
+	}
+
+	if versionCode == "" && outputVersionCode != "" {
+		versionCode = outputVersionCode
+		log.Info("Using " + versionCode + " as version code from output-metadata.json")
+	}
+
+	if versionName == "" && outputVersionName != "" {
+		versionName = outputVersionName
+		log.Info("Using " + versionName + " as version name from output-metadata.json")
+	}
+
+	return applicationId, versionCode, versionName
+}
+
+// GetFeatureName - Gets the name of the dynamic feature that a module's variant builds, from the split attribute of
+// its merged manifest, or an empty string for applications
+func GetFeatureName(buildDir string, variant string) string {
+	manifestPath := FindVariantManifest(buildDir, variant)
+	if manifestPath == "" {
+		return ""
+	}
+
+	manifest, err := ParseAndroidManifestXML(manifestPath)
+	if err != nil {
+		return ""
+	}
+
+	return manifest.Split
+}
+
+// ReadAppMetadata - Reads the app-metadata.properties of a variant, which records the Android Gradle Plugin version
+// that built it. Only application modules have app metadata
+func ReadAppMetadata(buildDir string, variant string) (map[string]string, error) {
+	file, err := os.Open(filepath.Join(buildDir, "intermediates", "app_metadata", variant, "app-metadata.properties"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if key, value, found := strings.Cut(line, "="); found {
+			properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return properties, scanner.Err()
+}
+
+// IsApplicationModule - Checks whether a module's build directory is from an application rather than a library, which
+// have AAR metadata instead of app metadata. Builds from versions without either are assumed to be applications
+func IsApplicationModule(buildDir string) bool {
+	return utils.FileExists(filepath.Join(buildDir, "intermediates", "app_metadata")) ||
+		!utils.FileExists(filepath.Join(buildDir, "intermediates", "aar_metadata"))
+}
+
+// GetManifestBuildUuid - Gets the build UUID set in the meta-data of a manifest, or an empty string when the build UUID
+// isn't set and so comes from the dex files
+func GetManifestBuildUuid(manifestPath string) string {
+	manifest, err := ParseAndroidManifestXML(manifestPath)
+	if err != nil {
+		return ""
+	}
+
+	for i, name := range manifest.Application.MetaData.Name {
+		if name == "com.bugsnag.android.BUILD_UUID" && i < len(manifest.Application.MetaData.Value) {
+			return manifest.Application.MetaData.Value[i]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,113 @@
+package android
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+)
+
+// ntGnuBuildId - The note type used by the GNU linkers for build IDs
+const ntGnuBuildId = 3
+
+// ElfInfo - The details used to identify a shared object or its symbol file
+type ElfInfo struct {
+	BuildId string
+	Arch    string
+}
+
+// GetElfInfo - Reads the GNU build ID and Android ABI of an ELF file
+func GetElfInfo(file string) (*ElfInfo, error) {
+	elfFile, err := elf.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ELF file %s: %w", file, err)
+	}
+	defer elfFile.Close()
+
+	buildId, err := GetElfBuildId(elfFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ElfInfo{BuildId: buildId, Arch: GetElfArch(elfFile.Machine)}, nil
+}
+
+// GetElfBuildId - Gets the GNU build ID from the note sections of an ELF file as a hex string
+func GetElfBuildId(elfFile *elf.File) (string, error) {
+	for _, section := range elfFile.Sections {
+		if section.Type != elf.SHT_NOTE {
+			continue
+		}
+
+		data, err := section.Data()
+		if err != nil {
+			return "", fmt.Errorf("unable to read %s: %w", section.Name, err)
+		}
+
+		if buildId, found := findGnuBuildId(data, elfFile.ByteOrder); found {
+			return buildId, nil
+		}
+	}
+
+	// Sections can be stripped from shared objects, but the notes are always loaded through a segment
+	for _, prog := range elfFile.Progs {
+		if prog.Type != elf.PT_NOTE || prog.Filesz == 0 {
+			continue
+		}
+
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			continue
+		}
+
+		if buildId, found := findGnuBuildId(data, elfFile.ByteOrder); found {
+			return buildId, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find buildId")
+}
+
+// findGnuBuildId - Walks the notes in a note section or segment looking for the GNU build ID
+func findGnuBuildId(data []byte, byteOrder binary.ByteOrder) (string, bool) {
+	for len(data) >= 12 {
+		nameSize := uint64(byteOrder.Uint32(data[0:4]))
+		descSize := uint64(byteOrder.Uint32(data[4:8]))
+		noteType := byteOrder.Uint32(data[8:12])
+
+		nameStart := uint64(12)
+		descStart := nameStart + alignUp(nameSize, 4)
+		next := descStart + alignUp(descSize, 4)
+
+		if descStart+descSize > uint64(len(data)) {
+			return "", false
+		}
+
+		name := data[nameStart : nameStart+nameSize]
+		if noteType == ntGnuBuildId && string(name) == "GNU\x00" && descSize > 0 {
+			return fmt.Sprintf("%x", data[descStart:descStart+descSize]), true
+		}
+
+		if next > uint64(len(data)) {
+			return "", false
+		}
+		data = data[next:]
+	}
+
+	return "", false
+}
+
+// GetElfArch - Gets the Android ABI name of an ELF machine type
+func GetElfArch(machine elf.Machine) string {
+	switch machine {
+	case elf.EM_AARCH64:
+		return "arm64-v8a"
+	case elf.EM_ARM:
+		return "armeabi-v7a"
+	case elf.EM_386:
+		return "x86"
+	case elf.EM_X86_64:
+		return "x86_64"
+	}
+
+	return ""
+}
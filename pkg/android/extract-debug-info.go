@@ -0,0 +1,467 @@
+package android
+
+import (
+	"bytes"
+	"compress/zlib"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// maxSectionAlignment - The largest section alignment accepted, which is far larger than any real section needs
+const maxSectionAlignment = 1 << 16
+
+// elfFile - The raw headers of an ELF file, kept as read so that they can be written back out unchanged
+type elfFile struct {
+	reader     io.ReaderAt
+	size       uint64
+	byteOrder  binary.ByteOrder
+	is64Bit    bool
+	header     elfHeader
+	programs   []elf.Prog64
+	sections   []elf.Section64
+	headerSize uint64
+}
+
+// elfHeader - The fields of the ELF file header that locate the program and section headers, in a class-independent form
+type elfHeader struct {
+	ident     [elf.EI_NIDENT]byte
+	fileType  uint16
+	machine   uint16
+	version   uint32
+	entry     uint64
+	phoff     uint64
+	shoff     uint64
+	flags     uint32
+	ehsize    uint16
+	phentsize uint16
+	phnum     uint16
+	shentsize uint16
+	shnum     uint16
+	shstrndx  uint16
+}
+
+// ExtractDebugInfo - Writes a copy of an ELF file containing only the information needed for symbolication, as
+// `llvm-objcopy --only-keep-debug --compress-debug-sections=zlib` does, and returns the path to the .so.sym file.
+// Loaded sections other than notes are replaced by empty (NOBITS) sections, while the symbol tables, notes and
+// debug sections are kept, with uncompressed DWARF sections compressed using zlib.
+// The .so.sym file keeps the name of the shared object and is written to a directory within outputPath named after
+// the directory it came from (its ABI), so that the shared objects of each ABI don't overwrite each other.
+func ExtractDebugInfo(file string, outputPath string) (string, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer input.Close()
+
+	inputInfo, err := input.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	elfFile, err := readElfFile(input, inputInfo.Size())
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid ELF file: %w", filepath.Base(file), err)
+	}
+
+	output, err := elfFile.extractDebugInfo()
+	if err != nil {
+		return "", fmt.Errorf("unable to extract debug info from %s: %w", filepath.Base(file), err)
+	}
+
+	outputFile, err := uniqueOutputFile(outputPath, file)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(outputFile, output, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return outputFile, nil
+}
+
+// uniqueOutputFile - Creates a directory within outputPath, named after the directory of the file, to write its .so.sym
+// file to, adding a number to the name if a file of the same name has already been written there
+func uniqueOutputFile(outputPath string, file string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + ".so.sym"
+	dirName := filepath.Base(filepath.Dir(file))
+	outputDir := filepath.Join(outputPath, dirName)
+
+	for i := 2; utils.FileExists(filepath.Join(outputDir, name)); i++ {
+		outputDir = filepath.Join(outputPath, fmt.Sprintf("%s-%d", dirName, i))
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(outputDir, name), nil
+}
+
+// readElfFile - Reads the file, program and section headers of an ELF file
+func readElfFile(reader io.ReaderAt, size int64) (*elfFile, error) {
+	var ident [elf.EI_NIDENT]byte
+	if _, err := reader.ReadAt(ident[:], 0); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(ident[0:4], []byte(elf.ELFMAG)) {
+		return nil, fmt.Errorf("bad magic number")
+	}
+
+	file := &elfFile{reader: reader, size: uint64(size)}
+
+	switch elf.Data(ident[elf.EI_DATA]) {
+	case elf.ELFDATA2LSB:
+		file.byteOrder = binary.LittleEndian
+	case elf.ELFDATA2MSB:
+		file.byteOrder = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("unknown data encoding %d", ident[elf.EI_DATA])
+	}
+
+	sectionReader := io.NewSectionReader(reader, 0, 1<<62)
+
+	switch elf.Class(ident[elf.EI_CLASS]) {
+	case elf.ELFCLASS64:
+		file.is64Bit = true
+		var header elf.Header64
+		if err := binary.Read(sectionReader, file.byteOrder, &header); err != nil {
+			return nil, err
+		}
+		file.header = elfHeader{header.Ident, header.Type, header.Machine, header.Version, header.Entry, header.Phoff,
+			header.Shoff, header.Flags, header.Ehsize, header.Phentsize, header.Phnum, header.Shentsize, header.Shnum, header.Shstrndx}
+		file.headerSize = uint64(binary.Size(header))
+	case elf.ELFCLASS32:
+		var header elf.Header32
+		if err := binary.Read(sectionReader, file.byteOrder, &header); err != nil {
+			return nil, err
+		}
+		file.header = elfHeader{header.Ident, header.Type, header.Machine, header.Version, uint64(header.Entry),
+			uint64(header.Phoff), uint64(header.Shoff), header.Flags, header.Ehsize, header.Phentsize, header.Phnum,
+			header.Shentsize, header.Shnum, header.Shstrndx}
+		file.headerSize = uint64(binary.Size(header))
+	default:
+		return nil, fmt.Errorf("unknown class %d", ident[elf.EI_CLASS])
+	}
+
+	if file.header.shnum == 0 || file.header.shstrndx == uint16(elf.SHN_XINDEX) {
+		return nil, fmt.Errorf("files with no section headers or extended section numbering are not supported")
+	}
+
+	if file.header.phoff+uint64(file.header.phnum)*uint64(file.header.phentsize) > file.size ||
+		file.header.shoff+uint64(file.header.shnum)*uint64(file.header.shentsize) > file.size {
+		return nil, fmt.Errorf("headers extend beyond the end of the file")
+	}
+
+	for i := 0; i < int(file.header.phnum); i++ {
+		program, err := file.readProgramHeader(file.header.phoff + uint64(i)*uint64(file.header.phentsize))
+		if err != nil {
+			return nil, err
+		}
+		file.programs = append(file.programs, program)
+	}
+
+	for i := 0; i < int(file.header.shnum); i++ {
+		section, err := file.readSectionHeader(file.header.shoff + uint64(i)*uint64(file.header.shentsize))
+		if err != nil {
+			return nil, err
+		}
+		file.sections = append(file.sections, section)
+	}
+
+	return file, nil
+}
+
+// readProgramHeader - Reads a program header, widening 32-bit headers to the 64-bit layout
+func (f *elfFile) readProgramHeader(offset uint64) (elf.Prog64, error) {
+	reader := io.NewSectionReader(f.reader, int64(offset), int64(f.header.phentsize))
+
+	if f.is64Bit {
+		var program elf.Prog64
+		err := binary.Read(reader, f.byteOrder, &program)
+		return program, err
+	}
+
+	var program elf.Prog32
+	err := binary.Read(reader, f.byteOrder, &program)
+
+	return elf.Prog64{Type: program.Type, Flags: program.Flags, Off: uint64(program.Off), Vaddr: uint64(program.Vaddr),
+		Paddr: uint64(program.Paddr), Filesz: uint64(program.Filesz), Memsz: uint64(program.Memsz), Align: uint64(program.Align)}, err
+}
+
+// readSectionHeader - Reads a section header, widening 32-bit headers to the 64-bit layout
+func (f *elfFile) readSectionHeader(offset uint64) (elf.Section64, error) {
+	reader := io.NewSectionReader(f.reader, int64(offset), int64(f.header.shentsize))
+
+	if f.is64Bit {
+		var section elf.Section64
+		err := binary.Read(reader, f.byteOrder, &section)
+		return section, err
+	}
+
+	var section elf.Section32
+	err := binary.Read(reader, f.byteOrder, &section)
+
+	return elf.Section64{Name: section.Name, Type: section.Type, Flags: uint64(section.Flags), Addr: uint64(section.Addr),
+		Off: uint64(section.Off), Size: uint64(section.Size), Link: section.Link, Info: section.Info,
+		Addralign: uint64(section.Addralign), Entsize: uint64(section.Entsize)}, err
+}
+
+// extractDebugInfo - Lays out and writes the debug-only copy of the ELF file
+func (f *elfFile) extractDebugInfo() ([]byte, error) {
+	output := &bytes.Buffer{}
+
+	// The file and program headers stay where they were, so that the offsets of any notes that follow them don't change
+	programHeadersEnd := f.header.phoff + uint64(f.header.phnum)*uint64(f.header.phentsize)
+	cursor := f.headerSize
+	if f.header.phnum > 0 && programHeadersEnd > cursor {
+		cursor = programHeadersEnd
+	}
+	output.Write(make([]byte, cursor))
+
+	// Sections are kept at their original offsets until the first one that has to move, so that the start of the
+	// file (and the segments that describe it) matches the original
+	preservedEnd := cursor
+	preserving := true
+
+	sections := make([]elf.Section64, len(f.sections))
+	newOffsets := make(map[uint64]uint64)
+
+	for i, section := range f.sections {
+		sections[i] = section
+
+		if i == 0 {
+			continue
+		}
+
+		if elf.SectionType(section.Type) == elf.SHT_NOBITS {
+			sections[i].Off = cursor
+			continue
+		}
+
+		if elf.SectionFlag(section.Flags)&elf.SHF_ALLOC != 0 && elf.SectionType(section.Type) != elf.SHT_NOTE {
+			sections[i].Type = uint32(elf.SHT_NOBITS)
+			sections[i].Off = cursor
+			continue
+		}
+
+		if section.Off+section.Size > f.size || section.Off+section.Size < section.Off {
+			return nil, fmt.Errorf("section %d extends beyond the end of the file", i)
+		}
+
+		if section.Addralign > maxSectionAlignment {
+			return nil, fmt.Errorf("section %d has an invalid alignment of %d", i, section.Addralign)
+		}
+
+		data := make([]byte, section.Size)
+		if _, err := f.reader.ReadAt(data, int64(section.Off)); err != nil {
+			return nil, fmt.Errorf("unable to read section %d: %w", i, err)
+		}
+
+		if f.shouldCompress(section) {
+			compressed, err := f.compressSection(section, data)
+			if err != nil {
+				return nil, err
+			}
+
+			data = compressed
+			sections[i].Flags |= uint64(elf.SHF_COMPRESSED)
+			sections[i].Size = uint64(len(data))
+			sections[i].Addralign = f.compressionHeaderAlignment()
+		}
+
+		var offset uint64
+		if preserving && section.Off >= cursor && elf.SectionFlag(section.Flags)&elf.SHF_ALLOC != 0 {
+			offset = section.Off
+		} else {
+			preserving = false
+			offset = alignUp(cursor, sections[i].Addralign)
+		}
+
+		output.Write(make([]byte, offset-cursor))
+		output.Write(data)
+
+		sections[i].Off = offset
+		newOffsets[section.Off] = offset
+		cursor = offset + uint64(len(data))
+
+		if preserving {
+			preservedEnd = cursor
+		}
+	}
+
+	// The program headers are kept so that load addresses can still be worked out, but only describe the data that's
+	// still in the file
+	programs := make([]elf.Prog64, len(f.programs))
+	for i, program := range f.programs {
+		programs[i] = program
+
+		if newOffset, ok := newOffsets[program.Off]; ok && elf.ProgType(program.Type) == elf.PT_NOTE {
+			programs[i].Off = newOffset
+		} else if program.Off >= preservedEnd {
+			programs[i].Filesz = 0
+		} else if program.Off+program.Filesz > preservedEnd {
+			programs[i].Filesz = preservedEnd - program.Off
+		}
+	}
+
+	header := f.header
+	header.shoff = alignUp(cursor, f.compressionHeaderAlignment())
+	header.phentsize = uint16(binary.Size(elf.Prog32{}))
+	header.shentsize = uint16(binary.Size(elf.Section32{}))
+	if f.is64Bit {
+		header.phentsize = uint16(binary.Size(elf.Prog64{}))
+		header.shentsize = uint16(binary.Size(elf.Section64{}))
+	}
+	output.Write(make([]byte, header.shoff-cursor))
+
+	for _, section := range sections {
+		if err := f.writeSectionHeader(output, section); err != nil {
+			return nil, err
+		}
+	}
+
+	data := output.Bytes()
+	headerBytes := &bytes.Buffer{}
+
+	if err := f.writeFileHeader(headerBytes, header); err != nil {
+		return nil, err
+	}
+
+	for _, program := range programs {
+		if err := f.writeProgramHeader(headerBytes, program); err != nil {
+			return nil, err
+		}
+	}
+
+	copy(data, headerBytes.Bytes()[:f.headerSize])
+	if f.header.phnum > 0 {
+		copy(data[f.header.phoff:], headerBytes.Bytes()[f.headerSize:])
+	}
+
+	return data, nil
+}
+
+// shouldCompress - Returns whether a section is uncompressed DWARF that should be compressed
+func (f *elfFile) shouldCompress(section elf.Section64) bool {
+	name := f.sectionName(section)
+
+	return strings.HasPrefix(name, ".debug") &&
+		section.Size > 0 &&
+		elf.SectionFlag(section.Flags)&(elf.SHF_ALLOC|elf.SHF_COMPRESSED) == 0
+}
+
+// compressSection - Compresses the data of a section with zlib, prefixed with the ELF compression header
+func (f *elfFile) compressSection(section elf.Section64, data []byte) ([]byte, error) {
+	compressed := &bytes.Buffer{}
+
+	var err error
+	if f.is64Bit {
+		err = binary.Write(compressed, f.byteOrder, elf.Chdr64{Type: uint32(elf.COMPRESS_ZLIB), Size: section.Size, Addralign: section.Addralign})
+	} else {
+		err = binary.Write(compressed, f.byteOrder, elf.Chdr32{Type: uint32(elf.COMPRESS_ZLIB), Size: uint32(section.Size), Addralign: uint32(section.Addralign)})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := zlib.NewWriterLevel(compressed, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return compressed.Bytes(), nil
+}
+
+// sectionName - Looks up the name of a section in the section header string table
+func (f *elfFile) sectionName(section elf.Section64) string {
+	if int(f.header.shstrndx) >= len(f.sections) {
+		return ""
+	}
+
+	names := f.sections[f.header.shstrndx]
+	if uint64(section.Name) >= names.Size || names.Off+names.Size > f.size {
+		return ""
+	}
+
+	name := make([]byte, names.Size-uint64(section.Name))
+	n, _ := f.reader.ReadAt(name, int64(names.Off+uint64(section.Name)))
+	name = name[:n]
+
+	if end := bytes.IndexByte(name, 0); end >= 0 {
+		name = name[:end]
+	}
+
+	return string(name)
+}
+
+// compressionHeaderAlignment - Returns the alignment of compressed sections and the section header table
+func (f *elfFile) compressionHeaderAlignment() uint64 {
+	if f.is64Bit {
+		return 8
+	}
+
+	return 4
+}
+
+// writeFileHeader - Writes the ELF file header in the file's class
+func (f *elfFile) writeFileHeader(writer io.Writer, header elfHeader) error {
+	if f.is64Bit {
+		return binary.Write(writer, f.byteOrder, elf.Header64{Ident: header.ident, Type: header.fileType,
+			Machine: header.machine, Version: header.version, Entry: header.entry, Phoff: header.phoff,
+			Shoff: header.shoff, Flags: header.flags, Ehsize: header.ehsize, Phentsize: header.phentsize,
+			Phnum: header.phnum, Shentsize: header.shentsize, Shnum: header.shnum, Shstrndx: header.shstrndx})
+	}
+
+	return binary.Write(writer, f.byteOrder, elf.Header32{Ident: header.ident, Type: header.fileType,
+		Machine: header.machine, Version: header.version, Entry: uint32(header.entry), Phoff: uint32(header.phoff),
+		Shoff: uint32(header.shoff), Flags: header.flags, Ehsize: header.ehsize, Phentsize: header.phentsize,
+		Phnum: header.phnum, Shentsize: header.shentsize, Shnum: header.shnum, Shstrndx: header.shstrndx})
+}
+
+// writeProgramHeader - Writes a program header in the file's class
+func (f *elfFile) writeProgramHeader(writer io.Writer, program elf.Prog64) error {
+	if f.is64Bit {
+		return binary.Write(writer, f.byteOrder, program)
+	}
+
+	return binary.Write(writer, f.byteOrder, elf.Prog32{Type: program.Type, Off: uint32(program.Off),
+		Vaddr: uint32(program.Vaddr), Paddr: uint32(program.Paddr), Filesz: uint32(program.Filesz),
+		Memsz: uint32(program.Memsz), Flags: program.Flags, Align: uint32(program.Align)})
+}
+
+// writeSectionHeader - Writes a section header in the file's class
+func (f *elfFile) writeSectionHeader(writer io.Writer, section elf.Section64) error {
+	if f.is64Bit {
+		return binary.Write(writer, f.byteOrder, section)
+	}
+
+	return binary.Write(writer, f.byteOrder, elf.Section32{Name: section.Name, Type: section.Type,
+		Flags: uint32(section.Flags), Addr: uint32(section.Addr), Off: uint32(section.Off), Size: uint32(section.Size),
+		Link: section.Link, Info: section.Info, Addralign: uint32(section.Addralign), Entsize: uint32(section.Entsize)})
+}
+
+// alignUp - Rounds an offset up to a multiple of the alignment
+func alignUp(offset uint64, alignment uint64) uint64 {
+	if alignment <= 1 {
+		return offset
+	}
+
+	return (offset + alignment - 1) / alignment * alignment
+}
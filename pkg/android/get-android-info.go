@@ -212,6 +212,10 @@ func BuildAndroidInfo(path string) (*AndroidManifestData, error) {
 		return nil, err
 	}
 
+	if isAxmlContent(buffer) {
+		return getAndroidAxmlData(path)
+	}
+
 	contentType := isXMLContent(buffer)
 
 	if contentType {
@@ -4,10 +4,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 )
 
+// AndroidVariant - A build variant of an application module, such as freeRelease in the app module
+type AndroidVariant struct {
+	Module   string
+	Name     string
+	Path     string
+	BuildDir string
+	Feature  string
+}
+
+// maxModuleDepth - How deep in a project to search for the build directories of modules, e.g. features/wear/build
+const maxModuleDepth = 3
+
 // BuildVariantsList - Returns a list of variants from a given path
 func BuildVariantsList(path string) ([]string, error) {
 	var variants []string
@@ -54,12 +70,295 @@ func GetVariantDirectory(path string) (string, error) {
 }
 
 func FindVariantDexFiles(mappingFilePath string, variant string) []string {
-	buildRoot := filepath.Join(filepath.Dir(mappingFilePath), "..", "..", "..", "intermediates", "dex", variant)
+	return FindBuildDexFiles(filepath.Join(filepath.Dir(mappingFilePath), "..", "..", ".."), variant)
+}
+
+// FindBuildDexFiles - Finds the directories containing the classes.dex files of a variant in a module's build
+// directory, so that every classesN.dex file is included in the build UUID
+func FindBuildDexFiles(buildDir string, variant string) []string {
+	buildRoot := filepath.Join(buildDir, "intermediates", "dex", variant)
 
 	if utils.IsDir(buildRoot) {
 		matches, _ := filepath.Glob(filepath.Join(buildRoot, "*", "classes.dex"))
-		return matches
+
+		var dexDirs []string
+		for _, match := range matches {
+			dexDirs = append(dexDirs, filepath.Dir(match))
+		}
+		return dexDirs
 	}
 
 	return []string{}
 }
+
+// String - Formats the variant as module:variant for display
+func (v AndroidVariant) String() string {
+	return v.Module + ":" + v.Name
+}
+
+// FindModules - Finds the modules in a project that have the given output in their build directory, such as
+// intermediates/merged_native_libs, with the app module listed first
+func FindModules(projectPath string, outputPath string) ([]string, error) {
+	var modules []string
+
+	err := filepath.WalkDir(projectPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+
+		if relativePath != "." {
+			name := entry.Name()
+			if name == "build" || name == "src" || name == "node_modules" || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+		}
+
+		if utils.FileExists(filepath.Join(path, "build", outputPath)) {
+			modules = append(modules, filepath.ToSlash(relativePath))
+		}
+
+		if strings.Count(filepath.ToSlash(relativePath), "/")+1 >= maxModuleDepth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(modules, func(i, j int) bool {
+		return modules[i] == "app" && modules[j] != "app"
+	})
+
+	return modules, nil
+}
+
+// GetVariants - Lists the variants in a build output directory, where each variant directory contains the given
+// marker file or directory. Older versions of the Android Gradle Plugin nest flavored variants as <flavor>/<buildType>,
+// which are named as Gradle does, e.g. free/release is freeRelease
+func GetVariants(path string, marker string) ([]AndroidVariant, error) {
+	var variants []AndroidVariant
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		variantPath := filepath.Join(path, entry.Name())
+		if utils.FileExists(filepath.Join(variantPath, marker)) {
+			variants = append(variants, AndroidVariant{Name: entry.Name(), Path: variantPath})
+			continue
+		}
+
+		buildTypes, err := os.ReadDir(variantPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, buildType := range buildTypes {
+			buildTypePath := filepath.Join(variantPath, buildType.Name())
+			if buildType.IsDir() && utils.FileExists(filepath.Join(buildTypePath, marker)) {
+				variants = append(variants, AndroidVariant{
+					Name: entry.Name() + cases.Title(language.Und, cases.NoLower).String(buildType.Name()),
+					Path: buildTypePath,
+				})
+			}
+		}
+	}
+
+	return variants, nil
+}
+
+// FindProjectVariants - Finds the variants of every module in a project with the given build output, then selects
+// those to upload: all of them with allVariants, those matching the given variant, or the only variant found
+func FindProjectVariants(projectPath string, outputPath string, marker string, variant string, allVariants bool) ([]AndroidVariant, error) {
+	modules, err := FindModules(projectPath, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("unable to find %s in any module of %s", filepath.Base(outputPath), projectPath)
+	}
+
+	var variants []AndroidVariant
+	for _, module := range modules {
+		buildDir := filepath.Join(projectPath, filepath.FromSlash(module), "build")
+
+		// Library modules can have the same outputs as applications, but aren't uploaded
+		if !IsApplicationModule(buildDir) {
+			continue
+		}
+
+		moduleVariants, err := GetVariants(filepath.Join(buildDir, outputPath), marker)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, moduleVariant := range moduleVariants {
+			moduleVariant.Module = module
+			moduleVariant.BuildDir = buildDir
+			moduleVariant.Feature = GetFeatureName(buildDir, moduleVariant.Name)
+			variants = append(variants, moduleVariant)
+		}
+	}
+
+	return SelectVariants(variants, variant, allVariants)
+}
+
+// FindFeatureVariants - Finds the dynamic feature modules of a project that were built for a variant of the app. R8
+// runs once in the app module, so feature modules have no mapping file of their own, only their own dex files
+func FindFeatureVariants(projectPath string, variant string) ([]AndroidVariant, error) {
+	modules, err := FindModules(projectPath, filepath.Join("intermediates", "dex"))
+	if err != nil {
+		return nil, err
+	}
+
+	var features []AndroidVariant
+	for _, module := range modules {
+		buildDir := filepath.Join(projectPath, filepath.FromSlash(module), "build")
+		dexPath := filepath.Join(buildDir, "intermediates", "dex", variant)
+
+		if !utils.IsDir(dexPath) {
+			continue
+		}
+
+		feature := GetFeatureName(buildDir, variant)
+		if feature == "" {
+			continue
+		}
+
+		features = append(features, AndroidVariant{Module: module, Name: variant, Path: dexPath, BuildDir: buildDir, Feature: feature})
+	}
+
+	return features, nil
+}
+
+// SelectVariants - Selects the variants to upload. A variant can be given as a name (e.g. freeRelease), a build type
+// that matches a single flavored variant of each module (e.g. release), or qualified with its module (e.g. wear:release).
+// Dynamic feature modules are selected along with the variant of the app that they are part of
+func SelectVariants(variants []AndroidVariant, variant string, allVariants bool) ([]AndroidVariant, error) {
+	var applications []AndroidVariant
+	var features []AndroidVariant
+
+	for _, candidate := range variants {
+		if candidate.Feature != "" {
+			features = append(features, candidate)
+		} else {
+			applications = append(applications, candidate)
+		}
+	}
+
+	if len(applications) == 0 {
+		return selectApplicationVariants(features, variant, allVariants)
+	}
+
+	selected, err := selectApplicationVariants(applications, variant, allVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectedFeatures []AndroidVariant
+	for _, feature := range features {
+		for _, application := range selected {
+			if feature.Name == application.Name {
+				selectedFeatures = append(selectedFeatures, feature)
+				break
+			}
+		}
+	}
+
+	return append(selected, selectedFeatures...), nil
+}
+
+// selectApplicationVariants - Selects the variants of application modules to upload
+func selectApplicationVariants(variants []AndroidVariant, variant string, allVariants bool) ([]AndroidVariant, error) {
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("no variants found. Please specify using `--variant`")
+	}
+
+	if allVariants {
+		return variants, nil
+	}
+
+	if variant == "" {
+		if len(variants) > 1 {
+			return nil, fmt.Errorf("more than one variant found (%s). Please specify using `--variant` or use `--all-variants` to upload all of them", formatVariants(variants))
+		}
+
+		return variants, nil
+	}
+
+	module := ""
+	if index := strings.LastIndex(variant, ":"); index >= 0 {
+		module = strings.ReplaceAll(strings.TrimPrefix(variant[:index], ":"), ":", "/")
+		variant = variant[index+1:]
+	}
+
+	var modules []string
+	for _, candidate := range variants {
+		if (module == "" || candidate.Module == module) && (len(modules) == 0 || modules[len(modules)-1] != candidate.Module) {
+			modules = append(modules, candidate.Module)
+		}
+	}
+
+	var selected []AndroidVariant
+	for _, module := range modules {
+		var matches []AndroidVariant
+		var flavoredMatches []AndroidVariant
+
+		for _, candidate := range variants {
+			if candidate.Module != module {
+				continue
+			}
+
+			if candidate.Name == variant {
+				matches = append(matches, candidate)
+			} else if strings.HasSuffix(candidate.Name, cases.Title(language.Und, cases.NoLower).String(variant)) {
+				flavoredMatches = append(flavoredMatches, candidate)
+			}
+		}
+
+		if len(matches) == 0 {
+			matches = flavoredMatches
+		}
+
+		if len(matches) > 1 {
+			return nil, fmt.Errorf("more than one variant matches %s (%s). Please specify the full variant name using `--variant`", variant, formatVariants(matches))
+		}
+
+		selected = append(selected, matches...)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("unable to find the %s variant, found %s", variant, formatVariants(variants))
+	}
+
+	return selected, nil
+}
+
+// formatVariants - Lists variants for display in errors
+func formatVariants(variants []AndroidVariant) string {
+	var names []string
+	for _, variant := range variants {
+		names = append(names, variant.String())
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -7,17 +7,21 @@ import (
 )
 
 func ReadAabManifest(path string) (map[string]string, error) {
-	aabManifestData := make(map[string]string)
-
 	content, err := os.ReadFile(path)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return ParseAabManifest(content)
+}
+
+// ParseAabManifest - Reads the application ID, version, API key and build UUID from the protobuf encoded manifest of an AAB
+func ParseAabManifest(content []byte) (map[string]string, error) {
+	aabManifestData := make(map[string]string)
 	rawAabManifestData := &proto_messages.XmlNode{}
 
-	err = proto.Unmarshal(content, rawAabManifestData)
+	err := proto.Unmarshal(content, rawAabManifestData)
 
 	if err != nil {
 		return nil, err
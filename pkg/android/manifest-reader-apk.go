@@ -0,0 +1,35 @@
+package android
+
+// ReadApkManifest - Reads the application ID, version, API key and build UUID from the binary XML manifest of an APK
+func ReadApkManifest(content []byte) (map[string]string, error) {
+	apkManifestData := make(map[string]string)
+
+	manifestData, err := ParseAxmlManifest(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestData.ApplicationId != "" {
+		apkManifestData["applicationId"] = manifestData.ApplicationId
+	}
+
+	if manifestData.VersionCode != "" {
+		apkManifestData["versionCode"] = manifestData.VersionCode
+	}
+
+	if manifestData.VersionName != "" {
+		apkManifestData["versionName"] = manifestData.VersionName
+	}
+
+	for i, name := range manifestData.Application.MetaData.Name {
+		switch name {
+		case "com.bugsnag.android.API_KEY":
+			apkManifestData["apiKey"] = manifestData.Application.MetaData.Value[i]
+		case "com.bugsnag.android.BUILD_UUID":
+			apkManifestData["buildUuid"] = manifestData.Application.MetaData.Value[i]
+		}
+	}
+
+	return apkManifestData, nil
+}
@@ -0,0 +1,72 @@
+package android
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// Resource IDs of the android:name and android:value attributes
+// https://developer.android.com/reference/android/R.attr#name
+const androidNameId uint32 = 16842755
+
+// https://developer.android.com/reference/android/R.attr#value
+const androidValueId uint32 = 16842788
+
+// ParseAxmlManifest - Pulls information from a binary XML (AXML) manifest, as compiled by aapt into APKs, into a struct
+func ParseAxmlManifest(content []byte) (*AndroidManifestData, error) {
+	manifest, err := decodeAxml(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Name != "manifest" {
+		return nil, fmt.Errorf("expected a <manifest> element but found <%s>", manifest.Name)
+	}
+
+	manifestData := &AndroidManifestData{
+		XMLName:       xml.Name{Local: "manifest"},
+		ApplicationId: manifest.attribute("package", 0),
+		VersionCode:   manifest.attribute("versionCode", AndroidVersionCodeId),
+		VersionName:   manifest.attribute("versionName", AndroidVersionNameId),
+		Application: AndroidManifestApplicationData{
+			XMLName: xml.Name{Local: "application"},
+			MetaData: AndroidManifestMetaData{
+				XMLName: xml.Name{Local: "meta-data"},
+			},
+		},
+	}
+
+	for _, application := range manifest.Children {
+		if application.Name != "application" {
+			continue
+		}
+
+		for _, metaData := range application.Children {
+			if metaData.Name != "meta-data" {
+				continue
+			}
+
+			manifestData.Application.MetaData.Name = append(manifestData.Application.MetaData.Name, metaData.attribute("name", androidNameId))
+			manifestData.Application.MetaData.Value = append(manifestData.Application.MetaData.Value, metaData.attribute("value", androidValueId))
+		}
+	}
+
+	return manifestData, nil
+}
+
+// getAndroidAxmlData - Pulls information from a binary XML (AXML) manifest file into a struct
+func getAndroidAxmlData(manifestFile string) (*AndroidManifestData, error) {
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", manifestFile, err)
+	}
+
+	manifestData, err := ParseAxmlManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse data from %s: %w", manifestFile, err)
+	}
+
+	return manifestData, nil
+}
@@ -11,6 +11,7 @@ type Manifest struct {
 	ApplicationId string      `xml:"package,attr"`
 	VersionCode   string      `xml:"versionCode,attr"`
 	VersionName   string      `xml:"versionName,attr"`
+	Split         string      `xml:"split,attr"`
 	Application   Application `xml:"application"`
 }
 
@@ -25,13 +26,36 @@ type MetaData struct {
 	Value   []string `xml:"value,attr"`
 }
 
-// ParseAndroidManifestXML - Pulls information from a human-readable xml file into a struct
+// ParseAndroidManifestXML - Pulls information from a human-readable or binary (AXML) xml file into a struct
 func ParseAndroidManifestXML(manifestFile string) (*Manifest, error) {
 	data, err := os.ReadFile(manifestFile)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read " + manifestFile + " : " + err.Error())
 	}
 
+	// Manifests compiled by aapt, such as those extracted from APKs, are in Android's binary XML format
+	if isAxmlContent(data) {
+		axmlData, err := ParseAxmlManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse data from " + manifestFile + " : " + err.Error())
+		}
+
+		return &Manifest{
+			XMLName:       axmlData.XMLName,
+			ApplicationId: axmlData.ApplicationId,
+			VersionCode:   axmlData.VersionCode,
+			VersionName:   axmlData.VersionName,
+			Application: Application{
+				XMLName: axmlData.Application.XMLName,
+				MetaData: MetaData{
+					XMLName: axmlData.Application.MetaData.XMLName,
+					Name:    axmlData.Application.MetaData.Name,
+					Value:   axmlData.Application.MetaData.Value,
+				},
+			},
+		}, nil
+	}
+
 	var manifestData *Manifest
 	err = xml.Unmarshal(data, &manifestData)
 	if err != nil {
@@ -0,0 +1,201 @@
+package android
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxMappingLineLength - The longest line read from a mapping file, which R8 can make long with inline frame data
+const maxMappingLineLength = 16 * 1024 * 1024
+
+// MappingFileInfo - The header written by R8 at the top of a mapping file and a summary of the mappings in it
+type MappingFileInfo struct {
+	Compiler        string
+	CompilerVersion string
+	MinApi          string
+	MapId           string
+	MapHash         string
+	Compressed      bool
+	Classes         int
+	FirstClassLine  int
+	Lines           int
+	InvalidLine     int
+	InvalidLineText string
+	Truncated       bool
+}
+
+// IsMappingClassLine - Checks whether a line of a ProGuard/R8 mapping file maps a class name, e.g. "com.example.Foo -> a.b:"
+func IsMappingClassLine(line string) bool {
+	if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+		return false
+	}
+
+	return strings.HasSuffix(line, ":") && strings.Contains(line, " -> ")
+}
+
+// isMappingMemberLine - Checks whether a line maps a field or method of the class above it, e.g. "    int count -> a"
+func isMappingMemberLine(line string) bool {
+	if line == "" || (line[0] != ' ' && line[0] != '\t') {
+		return false
+	}
+
+	_, target, found := strings.Cut(line, " -> ")
+	return found && strings.TrimSpace(target) != ""
+}
+
+// ReadMappingFile - Reads the header and summary of a ProGuard/R8 mapping file, which can be gzipped
+func ReadMappingFile(path string) (*MappingFileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if magic, _ := reader.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decompress %s: %w", path, err)
+		}
+		defer gzipReader.Close()
+
+		info, err := ParseMappingFile(gzipReader)
+		if info != nil {
+			info.Compressed = true
+		}
+		return info, err
+	}
+
+	return ParseMappingFile(reader)
+}
+
+// ParseMappingFile - Reads the header and summary of a ProGuard/R8 mapping file, noting the first line that isn't
+// a valid mapping and whether the file ends part way through a line
+func ParseMappingFile(reader io.Reader) (*MappingFileInfo, error) {
+	info := &MappingFileInfo{}
+	ending := &lastByteReader{reader: reader}
+	scanner := bufio.NewScanner(ending)
+	scanner.Buffer(make([]byte, 64*1024), maxMappingLineLength)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		info.Lines++
+
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			if info.Classes == 0 {
+				info.readHeaderLine(trimmed)
+			}
+		case IsMappingClassLine(line):
+			info.Classes++
+			if info.FirstClassLine == 0 {
+				info.FirstClassLine = info.Lines
+			}
+		case isMappingMemberLine(line) && info.Classes > 0:
+		default:
+			if info.InvalidLine == 0 {
+				info.InvalidLine = info.Lines
+				info.InvalidLineText = line
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			info.Truncated = true
+			return info, nil
+		}
+
+		return nil, err
+	}
+
+	// Mappings are written a line at a time, so an incomplete last line is a sign that the file was cut short
+	if info.Classes > 0 && info.InvalidLine == info.Lines && ending.last != '\n' {
+		info.Truncated = true
+	}
+
+	return info, nil
+}
+
+// readHeaderLine - Reads a "# key: value" header line written by R8
+func (info *MappingFileInfo) readHeaderLine(line string) {
+	key, value, found := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+	if !found {
+		return
+	}
+
+	value = strings.TrimSpace(value)
+
+	switch strings.TrimSpace(key) {
+	case "compiler":
+		info.Compiler = value
+	case "compiler_version":
+		info.CompilerVersion = value
+	case "min_api":
+		info.MinApi = value
+	case "pg_map_id":
+		info.MapId = value
+	case "pg_map_hash":
+		info.MapHash = value
+	}
+}
+
+// Validate - Checks that the file looks like a complete mapping file, so that empty, truncated or unrelated files
+// aren't uploaded. An invalid line after the first class is left to InvalidLineWarning, as R8 adds new kinds of line
+func (info *MappingFileInfo) Validate() error {
+	switch {
+	case info.Truncated:
+		return fmt.Errorf("the mapping file is truncated")
+	case info.Lines == 0:
+		return fmt.Errorf("the mapping file is empty")
+	case info.InvalidLine > 0 && (info.FirstClassLine == 0 || info.InvalidLine < info.FirstClassLine):
+		return fmt.Errorf("this doesn't look like a ProGuard/R8 mapping file, %s", info.InvalidLineWarning())
+	case info.Classes == 0 && !info.HasR8Header():
+		// R8 writes a header even when no classes are renamed, so only files without one are unexpected
+		return fmt.Errorf("the mapping file doesn't map any classes")
+	}
+
+	return nil
+}
+
+// HasR8Header - Checks whether the file starts with the header that R8 writes to identify the mapping
+func (info *MappingFileInfo) HasR8Header() bool {
+	return info.Compiler != "" || info.MapId != ""
+}
+
+// InvalidLineWarning - Describes the first line that isn't a valid mapping, or returns an empty string if there isn't one
+func (info *MappingFileInfo) InvalidLineWarning() string {
+	if info.InvalidLine == 0 {
+		return ""
+	}
+
+	text := info.InvalidLineText
+	if len(text) > 80 {
+		text = text[:80] + "..."
+	}
+
+	return fmt.Sprintf("line %d isn't a valid mapping: %q", info.InvalidLine, text)
+}
+
+// lastByteReader - Remembers the last byte read, to tell whether a file ends with a complete line
+type lastByteReader struct {
+	reader io.Reader
+	last   byte
+}
+
+func (r *lastByteReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.last = p[n-1]
+	}
+	return n, err
+}
@@ -1,6 +1,9 @@
 package android
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
@@ -18,9 +21,11 @@ func UploadAndroidNdk(
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	dryRun bool,
 ) error {
-	fileFieldData := make(map[string]string)
+	var tasks []func() error
 
 	numberOfFiles := len(fileList)
 
@@ -29,21 +34,39 @@ func UploadAndroidNdk(
 		return nil
 	}
 
+	buildIds := make(map[string]string)
+
 	for _, file := range fileList {
-		uploadOptions, err := utils.BuildAndroidNDKUploadOptions(apiKey, applicationId, versionName, versionCode, projectRoot, filepath.Base(file), overwrite)
+		var buildId, arch string
+		elfInfo, err := GetElfInfo(file)
 
 		if err != nil {
-			return err
-		}
+			log.Warn(fmt.Sprintf("Unable to read the build ID of %s: %s", file, err))
+		} else {
+			buildId = elfInfo.BuildId
+			arch = elfInfo.Arch
 
-		fileFieldData["soFile"] = file
+			if previousFile, ok := buildIds[buildId]; ok {
+				log.Warn(fmt.Sprintf("%s and %s have the same build ID (%s), only one of them will be used to symbolicate crashes", previousFile, file, buildId))
+			} else {
+				buildIds[buildId] = file
+			}
+		}
 
-		err = server.ProcessFileRequest(endpoint+"/ndk-symbol", uploadOptions, fileFieldData, timeout, retries, file, dryRun)
+		uploadOptions, err := utils.BuildAndroidNDKUploadOptions(apiKey, applicationId, versionName, versionCode, projectRoot, filepath.Base(file), buildId, arch, overwrite)
 
 		if err != nil {
 			return err
 		}
+
+		fileFieldData := make(map[string]string)
+		fileFieldData["soFile"] = file
+
+		file := file
+		tasks = append(tasks, func() error {
+			return server.ProcessFileRequest(endpoint+"/ndk-symbol", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, file, dryRun)
+		})
 	}
 
-	return nil
+	return server.ProcessConcurrently(concurrency, tasks)
 }
@@ -45,7 +45,7 @@ func (opts CreateBuildInfo) Override(base CreateBuildInfo) CreateBuildInfo {
 
 func (opts CreateBuildInfo) Validate() error {
 	if opts.ApiKey == "" {
-		return fmt.Errorf("missing api key, please specify using `--api-key`")
+		return fmt.Errorf("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable")
 	}
 
 	if opts.AppVersion == "" {
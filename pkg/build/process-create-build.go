@@ -6,6 +6,7 @@ import (
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"time"
 )
 
 type Payload struct {
@@ -27,10 +28,12 @@ type Payload struct {
 //   - endpoint: The target URL for the HTTP request.
 //   - dryRun: If true, the function performs a dry run without actually sending the request.
 //   - timeout: The maximum time allowed for the HTTP request.
+//   - retries: The number of times to retry the request in case of failure.
+//   - retryMaxDelay: The longest time to wait between two attempts.
 //
 // Returns:
 //   - error: An error if any step of the build processing fails. Nil if the process is successful.
-func ProcessCreateBuild(buildOptions CreateBuildInfo, endpoint string, dryRun bool, timeout int, retries int) error {
+func ProcessCreateBuild(buildOptions CreateBuildInfo, endpoint string, dryRun bool, timeout int, retries int, retryMaxDelay time.Duration) error {
 	buildPayload, err := json.Marshal(buildOptions)
 	if err != nil {
 		return fmt.Errorf("Failed to create build information payload: " + err.Error())
@@ -39,7 +42,7 @@ func ProcessCreateBuild(buildOptions CreateBuildInfo, endpoint string, dryRun bo
 	prettyBuildPayload, _ := utils.PrettyPrintJson(string(buildPayload))
 	log.Info("Build information:\n" + prettyBuildPayload)
 
-	err = server.ProcessBuildRequest(endpoint, buildPayload, timeout, retries, dryRun)
+	err = server.ProcessBuildRequest(endpoint, buildPayload, timeout, retries, retryMaxDelay, dryRun)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,116 @@
+package inspect
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+const (
+	aabManifestPath       = "base/manifest/AndroidManifest.xml"
+	aabProguardMapPath    = "BUNDLE-METADATA/com.android.tools.build.obfuscation/proguard.map"
+	aabDebugSymbolsPrefix = "BUNDLE-METADATA/com.android.tools.build.debugsymbols/"
+)
+
+// inspectAab - Reads the manifest details, mapping file and native libraries of an Android App Bundle
+func inspectAab(aabPath string) (*FileInfo, error) {
+	zipReader, err := zip.OpenReader(aabPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", aabPath, err)
+	}
+	defer zipReader.Close()
+
+	info := &FileInfo{Path: aabPath, Type: TypeAndroidAab, Metadata: make(map[string]string)}
+	hasManifest := false
+	hasProguardMap := false
+	dexFiles := 0
+	debugSymbols := 0
+
+	for _, file := range zipReader.File {
+		switch {
+		case file.Name == aabManifestPath:
+			hasManifest = true
+
+			content, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			manifestData, err := android.ParseAabManifest(content)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the manifest of %s: %w", aabPath, err)
+			}
+
+			log.AddSecret(manifestData["apiKey"])
+
+			for key, value := range manifestData {
+				info.Metadata[key] = value
+			}
+		case file.Name == aabProguardMapPath:
+			hasProguardMap = true
+
+			reader, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			mappingInfo, err := android.ParseMappingFile(reader)
+			reader.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			addMappingMetadata(info, mappingInfo, "proguard")
+
+			if err := mappingInfo.Validate(); err != nil {
+				info.Warnings = append(info.Warnings, "The bundled mapping file won't be uploaded as "+err.Error())
+			}
+		case strings.HasPrefix(file.Name, aabDebugSymbolsPrefix) && !file.FileInfo().IsDir():
+			debugSymbols++
+		case strings.HasSuffix(file.Name, ".dex"):
+			dexFiles++
+		case strings.HasSuffix(file.Name, ".so") && path.Base(path.Dir(path.Dir(file.Name))) == "lib":
+			// Native libraries are stored as <module>/lib/<abi>/<name>.so
+			info.Objects = append(info.Objects, map[string]string{
+				"name": path.Base(file.Name),
+				"abi":  path.Base(path.Dir(file.Name)),
+			})
+		}
+	}
+
+	info.Metadata["proguardMapping"] = yesNo(hasProguardMap)
+	info.Metadata["dexFiles"] = strconv.Itoa(dexFiles)
+
+	if len(info.Objects) > 0 || debugSymbols > 0 {
+		info.Metadata["nativeDebugSymbols"] = strconv.Itoa(debugSymbols)
+	}
+
+	if !hasManifest {
+		info.Warnings = append(info.Warnings, "No manifest found in this bundle")
+	} else if info.Metadata["buildUuid"] == "" {
+		info.Warnings = append(info.Warnings, "No build UUID found in the manifest, mapping files will be matched by version only")
+	}
+
+	if len(info.Objects) > 0 && debugSymbols == 0 {
+		info.Warnings = append(info.Warnings, "The bundle contains native libraries but no native debug symbols")
+	}
+
+	return info, nil
+}
+
+// readZipFile - Reads the contents of a file in a zip
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
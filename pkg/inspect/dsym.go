@@ -0,0 +1,120 @@
+package inspect
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// isMachO - Checks for the magic number of a thin or fat Mach-O file
+func isMachO(header []byte) bool {
+	if len(header) < 4 {
+		return false
+	}
+
+	switch binary.BigEndian.Uint32(header) {
+	case macho.Magic32, macho.Magic64, macho.MagicFat:
+		return true
+	}
+
+	switch binary.LittleEndian.Uint32(header) {
+	case macho.Magic32, macho.Magic64:
+		return true
+	}
+
+	return false
+}
+
+// inspectDsym - Reads the UUID and architecture of each slice of a dSYM, a zip of dSYMs or a DWARF file
+func inspectDsym(path string) ([]*FileInfo, error) {
+	var dwarfInfo []*ios.DwarfInfo
+
+	if !utils.IsDir(path) && !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		var err error
+		dwarfInfo, err = ios.GetMachOUuids(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dwarf := range dwarfInfo {
+			dwarf.Name = filepath.Base(path)
+			dwarf.Location = filepath.Dir(path)
+		}
+	} else {
+		var tempDir string
+		var err error
+		dwarfInfo, tempDir, err = ios.FindDsymsInPath(path, true, true)
+		defer os.RemoveAll(tempDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fileInfo []*FileInfo
+	infoByFile := make(map[string]*FileInfo)
+	debugInfoByFile := make(map[string]bool)
+
+	for _, dwarf := range dwarfInfo {
+		dwarfFile := filepath.Join(dwarf.Location, dwarf.Name)
+
+		// The files in a zip are extracted to a temporary directory, so are reported against the zip itself
+		infoPath := dwarfFile
+		if strings.HasSuffix(strings.ToLower(path), ".zip") {
+			infoPath = path
+		}
+
+		info, ok := infoByFile[infoPath]
+		if !ok {
+			info = &FileInfo{Path: infoPath, Type: TypeDsym}
+			infoByFile[infoPath] = info
+			fileInfo = append(fileInfo, info)
+		}
+
+		hasDebugInfo, checked := debugInfoByFile[dwarfFile]
+		if !checked {
+			hasDebugInfo = machOHasDebugInfo(dwarfFile)
+			debugInfoByFile[dwarfFile] = hasDebugInfo
+
+			if !hasDebugInfo {
+				info.Warnings = append(info.Warnings, dwarf.Name+" has no DWARF debug information")
+			}
+		}
+
+		info.Objects = append(info.Objects, map[string]string{
+			"name":      dwarf.Name,
+			"arch":      dwarf.Arch,
+			"uuid":      dwarf.UUID,
+			"debugInfo": yesNo(hasDebugInfo),
+		})
+	}
+
+	return fileInfo, nil
+}
+
+// machOHasDebugInfo - Checks whether every slice of a Mach-O file has a __debug_info section
+func machOHasDebugInfo(path string) bool {
+	if fatFile, err := macho.OpenFat(path); err == nil {
+		defer fatFile.Close()
+
+		for _, arch := range fatFile.Arches {
+			if arch.Section("__debug_info") == nil {
+				return false
+			}
+		}
+
+		return len(fatFile.Arches) > 0
+	}
+
+	file, err := macho.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	return file.Section("__debug_info") != nil
+}
@@ -0,0 +1,64 @@
+package inspect
+
+import (
+	"debug/elf"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+)
+
+// dartSymbolFileRegex - Flutter names its symbol files after the platform and architecture, e.g. app.android-arm64.symbols
+var dartSymbolFileRegex = regexp.MustCompile(`\.([a-z]+)-([^.]+)\.symbols$`)
+
+// inspectElf - Reads the build ID, architecture and debug information of an NDK shared object or Dart symbol file
+func inspectElf(path string, fileType string) (*FileInfo, error) {
+	elfFile, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read ELF file %s: %w", path, err)
+	}
+	defer elfFile.Close()
+
+	info := &FileInfo{Path: path, Type: fileType, Metadata: make(map[string]string)}
+
+	buildId, err := android.GetElfBuildId(elfFile)
+	if err == nil {
+		info.Metadata["buildId"] = buildId
+	} else {
+		info.Warnings = append(info.Warnings, "No GNU build ID found, crashes can't be matched to this file")
+	}
+
+	if match := dartSymbolFileRegex.FindStringSubmatch(filepath.Base(path)); fileType == TypeDart && match != nil {
+		info.Metadata["platform"] = match[1]
+		info.Metadata["arch"] = match[2]
+	} else if arch := android.GetElfArch(elfFile.Machine); arch != "" {
+		info.Metadata["arch"] = arch
+	} else {
+		info.Metadata["arch"] = elfFile.Machine.String()
+	}
+
+	hasDebugInfo := elfHasSection(elfFile, ".debug_info") || elfHasSection(elfFile, ".zdebug_info")
+	hasSymbolTable := elfHasSection(elfFile, ".symtab")
+	info.Metadata["debugInfo"] = yesNo(hasDebugInfo)
+	info.Metadata["symbolTable"] = yesNo(hasSymbolTable)
+
+	if hasSymbolTable {
+		if symbols, err := elfFile.Symbols(); err == nil {
+			info.Metadata["symbols"] = strconv.Itoa(len(symbols))
+		}
+	}
+
+	if !hasDebugInfo && !hasSymbolTable {
+		info.Warnings = append(info.Warnings, "No debug information or symbol table found, stack frames can't be symbolicated with this file")
+	}
+
+	return info, nil
+}
+
+// elfHasSection - Checks whether an ELF file has a section with contents in the file
+func elfHasSection(elfFile *elf.File, name string) bool {
+	section := elfFile.Section(name)
+	return section != nil && section.Type != elf.SHT_NOBITS && section.Size > 0
+}
@@ -0,0 +1,283 @@
+package inspect
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// File types
+const (
+	TypeDsym            = "dsym"
+	TypeNdk             = "android-ndk"
+	TypeDart            = "dart"
+	TypeAndroidProguard = "android-proguard"
+	TypeSourceMap       = "source-map"
+	TypeAndroidAab      = "android-aab"
+)
+
+// gzipMagic - The first bytes of a gzipped file
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// FileInfo - The identifying metadata of a symbol or mapping file, as used to match it to crashes
+type FileInfo struct {
+	Path     string              `json:"file"`
+	Type     string              `json:"fileType"`
+	Metadata map[string]string   `json:"metadata,omitempty"`
+	Objects  []map[string]string `json:"objects,omitempty"`
+	Sources  []string            `json:"sources,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+}
+
+// PrintFileInfo - Inspects each of the given files (or the files within the given directories) and prints their metadata
+func PrintFileInfo(paths []string) error {
+	var fileInfo []*FileInfo
+
+	for _, path := range paths {
+		info, err := InspectPath(path)
+		if err != nil {
+			return err
+		}
+
+		fileInfo = append(fileInfo, info...)
+	}
+
+	if len(fileInfo) == 0 {
+		return fmt.Errorf("no symbol or mapping files found in %s", strings.Join(paths, ", "))
+	}
+
+	for _, info := range fileInfo {
+		if log.IsJson() {
+			log.PrintJson(struct {
+				Type string `json:"type"`
+				*FileInfo
+			}{"inspect", info})
+		} else {
+			fmt.Print(log.Redact(info.String()))
+		}
+	}
+
+	return nil
+}
+
+// InspectPath - Reads the metadata of a symbol or mapping file. Directories, other than dSYMs, are searched for
+// any symbol or mapping files within them
+func InspectPath(path string) ([]*FileInfo, error) {
+	if !utils.IsDir(path) {
+		info, err := InspectFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*FileInfo{info}, nil
+	}
+
+	if strings.HasSuffix(strings.ToLower(filepath.Clean(path)), ".dsym") {
+		return inspectDsym(path)
+	}
+
+	var fileInfo []*FileInfo
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if strings.HasSuffix(strings.ToLower(info.Name()), ".dsym") {
+				dsymInfo, err := inspectDsym(file)
+				if err == nil {
+					fileInfo = append(fileInfo, dsymInfo...)
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Files that aren't symbol or mapping files are expected when searching a directory
+		if fileType, err := detectFileType(file); err == nil && fileType != "" {
+			if inspected, err := InspectFile(file); err == nil {
+				fileInfo = append(fileInfo, inspected)
+			}
+		}
+
+		return nil
+	})
+
+	return fileInfo, err
+}
+
+// InspectFile - Reads the metadata of a single symbol or mapping file
+func InspectFile(path string) (*FileInfo, error) {
+	fileType, err := detectFileType(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch fileType {
+	case TypeDsym:
+		dsymInfo, err := inspectDsym(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(dsymInfo) == 1 {
+			return dsymInfo[0], nil
+		}
+
+		// A zip of several dSYMs is reported as one file with an object for each slice
+		info := &FileInfo{Path: path, Type: TypeDsym}
+		for _, dsym := range dsymInfo {
+			info.Objects = append(info.Objects, dsym.Objects...)
+		}
+		return info, nil
+	case TypeNdk, TypeDart:
+		return inspectElf(path, fileType)
+	case TypeAndroidProguard:
+		return inspectProguardMapping(path)
+	case TypeSourceMap:
+		return inspectSourceMap(path)
+	case TypeAndroidAab:
+		return inspectAab(path)
+	}
+
+	return nil, fmt.Errorf("%s is not a recognised symbol or mapping file", path)
+}
+
+// detectFileType - Works out the type of a file from its magic number, contents or extension
+func detectFileType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		if strings.HasSuffix(path, ".symbols") {
+			return TypeDart, nil
+		}
+		return TypeNdk, nil
+	case isMachO(header):
+		return TypeDsym, nil
+	case bytes.HasPrefix(header, []byte("PK")):
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".aab":
+			return TypeAndroidAab, nil
+		case ".zip":
+			return TypeDsym, nil
+		}
+		return "", nil
+	}
+
+	content := header
+	if bytes.HasPrefix(header, gzipMagic) {
+		content, err = readGzipHeader(path)
+		if err != nil {
+			return "", nil
+		}
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	isSourceMapName := strings.HasSuffix(strings.TrimSuffix(strings.ToLower(path), ".gz"), ".map")
+	if bytes.HasPrefix(trimmed, []byte("{")) && (isSourceMapName || bytes.Contains(content, []byte(`"mappings"`))) {
+		return TypeSourceMap, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if android.IsMappingClassLine(scanner.Text()) {
+			return TypeAndroidProguard, nil
+		}
+	}
+
+	return "", nil
+}
+
+// readGzipHeader - Reads the start of the uncompressed contents of a gzipped file
+func readGzipHeader(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, 4096)
+	n, err := io.ReadFull(reader, content)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	return content[:n], nil
+}
+
+// String - Formats the file info for display
+func (info *FileInfo) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("%s (%s)\n", info.Path, info.Type))
+
+	for _, key := range sortedKeys(info.Metadata) {
+		builder.WriteString(fmt.Sprintf("  %s: %s\n", key, info.Metadata[key]))
+	}
+
+	for _, object := range info.Objects {
+		var fields []string
+		for _, key := range sortedKeys(object) {
+			fields = append(fields, key+"="+object[key])
+		}
+		builder.WriteString("  - " + strings.Join(fields, " ") + "\n")
+	}
+
+	if len(info.Sources) > 0 {
+		builder.WriteString(fmt.Sprintf("  sources (%d):\n", len(info.Sources)))
+		for _, source := range info.Sources {
+			builder.WriteString("    " + source + "\n")
+		}
+	}
+
+	for _, warning := range info.Warnings {
+		builder.WriteString("  warning: " + warning + "\n")
+	}
+
+	return builder.String()
+}
+
+// sortedKeys - Returns the keys of a map in alphabetical order
+func sortedKeys(values map[string]string) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// yesNo - Formats a boolean for display
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
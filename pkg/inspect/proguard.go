@@ -0,0 +1,55 @@
+package inspect
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+)
+
+// inspectProguardMapping - Reads the R8 header and number of classes of a (possibly gzipped) ProGuard/R8 mapping file
+func inspectProguardMapping(path string) (*FileInfo, error) {
+	mappingInfo, err := android.ReadMappingFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &FileInfo{Path: path, Type: TypeAndroidProguard, Metadata: make(map[string]string)}
+	addMappingMetadata(info, mappingInfo, "")
+
+	if mappingInfo.Compressed {
+		info.Metadata["compressed"] = "yes"
+	}
+
+	if err := mappingInfo.Validate(); err != nil {
+		info.Warnings = append(info.Warnings, "This file won't be uploaded as "+err.Error())
+	} else if warning := mappingInfo.InvalidLineWarning(); warning != "" {
+		info.Warnings = append(info.Warnings, warning)
+	}
+
+	return info, nil
+}
+
+// addMappingMetadata - Adds the class count and any R8 header values of a mapping file to the metadata, with the
+// keys prefixed for mapping files bundled in other files, e.g. proguardMapId
+func addMappingMetadata(info *FileInfo, mappingInfo *android.MappingFileInfo, prefix string) {
+	values := map[string]string{
+		"Classes":         strconv.Itoa(mappingInfo.Classes),
+		"Compiler":        mappingInfo.Compiler,
+		"CompilerVersion": mappingInfo.CompilerVersion,
+		"MapId":           mappingInfo.MapId,
+		"MapHash":         mappingInfo.MapHash,
+	}
+
+	for name, value := range values {
+		if value == "" {
+			continue
+		}
+
+		if prefix == "" {
+			name = strings.ToLower(name[:1]) + name[1:]
+		}
+
+		info.Metadata[prefix+name] = value
+	}
+}
@@ -0,0 +1,72 @@
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// sourceMap - The fields of a source map that identify it, index maps contain a source map for each section
+type sourceMap struct {
+	Version  int      `json:"version"`
+	File     string   `json:"file"`
+	Sources  []string `json:"sources"`
+	Mappings *string  `json:"mappings"`
+	Sections []struct {
+		Map *sourceMap `json:"map"`
+	} `json:"sections"`
+}
+
+// inspectSourceMap - Reads the version, file and sources of a JavaScript source map
+func inspectSourceMap(path string) (*FileInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content sourceMap
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("unable to parse source map %s: %w", path, err)
+	}
+
+	info := &FileInfo{Path: path, Type: TypeSourceMap, Metadata: make(map[string]string)}
+	info.Metadata["version"] = strconv.Itoa(content.Version)
+
+	if content.File != "" {
+		info.Metadata["file"] = content.File
+	}
+
+	if len(content.Sections) > 0 {
+		info.Metadata["sections"] = strconv.Itoa(len(content.Sections))
+	}
+
+	info.Sources = sourceMapSources(&content)
+
+	if content.Version != 3 {
+		info.Warnings = append(info.Warnings, "Only version 3 source maps are supported")
+	}
+
+	if content.Mappings == nil && len(content.Sections) == 0 {
+		info.Warnings = append(info.Warnings, "No mappings found in this source map")
+	}
+
+	if len(info.Sources) == 0 {
+		info.Warnings = append(info.Warnings, "No sources found in this source map")
+	}
+
+	return info, nil
+}
+
+// sourceMapSources - Lists the sources of a source map, including those of each section of an index map
+func sourceMapSources(content *sourceMap) []string {
+	sources := append([]string(nil), content.Sources...)
+
+	for _, section := range content.Sections {
+		if section.Map != nil {
+			sources = append(sources, sourceMapSources(section.Map)...)
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,252 @@
+package ios
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+// AppStoreConnectApiRootUrl is the root URL of the App Store Connect API
+const AppStoreConnectApiRootUrl = "https://api.appstoreconnect.apple.com"
+
+// appStoreConnectTokenLifetime is how long an API token is valid for, App Store Connect rejects tokens over 20 minutes
+const appStoreConnectTokenLifetime = 15 * time.Minute
+
+// HttpClient sends HTTP requests, allowing the App Store Connect client to be tested against a local server
+type HttpClient interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// AppStoreConnectClient downloads dSYMs from the App Store Connect API using an API key
+type AppStoreConnectClient struct {
+	ApiRootUrl string
+	HttpClient HttpClient
+	keyId      string
+	issuerId   string
+	privateKey *ecdsa.PrivateKey
+}
+
+// appStoreConnectBuilds is the response from the builds endpoint, including the bundles of each build
+type appStoreConnectBuilds struct {
+	Data []struct {
+		Id string `json:"id"`
+	} `json:"data"`
+	Included []struct {
+		Type       string `json:"type"`
+		Id         string `json:"id"`
+		Attributes struct {
+			BundleId string `json:"bundleId"`
+			DsymUrl  string `json:"dSYMUrl"`
+		} `json:"attributes"`
+	} `json:"included"`
+}
+
+// appStoreConnectErrors is the error response from the App Store Connect API
+type appStoreConnectErrors struct {
+	Errors []struct {
+		Status string `json:"status"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// NewAppStoreConnectClient creates a client authenticated with the given API key ID, issuer ID and .p8 private key file
+func NewAppStoreConnectClient(keyId, issuerId, privateKeyPath string) (*AppStoreConnectClient, error) {
+	data, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, errors.Errorf("Unable to read the App Store Connect private key: %s", err)
+	}
+
+	privateKey, err := parseAppStoreConnectPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppStoreConnectClient{
+		ApiRootUrl: AppStoreConnectApiRootUrl,
+		HttpClient: http.DefaultClient,
+		keyId:      keyId,
+		issuerId:   issuerId,
+		privateKey: privateKey,
+	}, nil
+}
+
+// DownloadDsyms downloads the dSYM zips of a build to outputDir, returning their paths
+func (c *AppStoreConnectClient) DownloadDsyms(appId, buildNumber, outputDir string) ([]string, error) {
+	query := url.Values{}
+	query.Set("filter[app]", appId)
+	query.Set("filter[version]", buildNumber)
+	query.Set("include", "buildBundles")
+	query.Set("fields[buildBundles]", "bundleId,dSYMUrl")
+
+	var builds appStoreConnectBuilds
+	if err := c.getJson("/v1/builds?"+query.Encode(), &builds); err != nil {
+		return nil, err
+	}
+
+	if len(builds.Data) == 0 {
+		return nil, errors.Errorf("Unable to find build %s of app %s on App Store Connect", buildNumber, appId)
+	}
+
+	var dsymPaths []string
+	for i, bundle := range builds.Included {
+		if bundle.Type != "buildBundles" || bundle.Attributes.DsymUrl == "" {
+			continue
+		}
+
+		name := bundle.Attributes.BundleId
+		if name == "" {
+			name = fmt.Sprintf("bundle-%d", i)
+		}
+
+		dsymPath := filepath.Join(outputDir, name+".dSYM.zip")
+		log.Info("Downloading dSYMs for " + name + " from App Store Connect")
+
+		if err := c.download(bundle.Attributes.DsymUrl, dsymPath); err != nil {
+			return nil, err
+		}
+
+		dsymPaths = append(dsymPaths, dsymPath)
+	}
+
+	if len(dsymPaths) == 0 {
+		return nil, errors.Errorf("No dSYMs are available on App Store Connect for build %s of app %s", buildNumber, appId)
+	}
+
+	return dsymPaths, nil
+}
+
+// getJson sends an authenticated request to the App Store Connect API and decodes the JSON response
+func (c *AppStoreConnectClient) getJson(path string, result interface{}) error {
+	token, err := c.token(time.Now())
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("GET", strings.TrimSuffix(c.ApiRootUrl, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := c.HttpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending request to App Store Connect: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response from App Store Connect: %w", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		var apiErrors appStoreConnectErrors
+		if json.Unmarshal(body, &apiErrors) == nil && len(apiErrors.Errors) > 0 {
+			return errors.Errorf("App Store Connect returned %s: %s", response.Status, apiErrors.Errors[0].Detail)
+		}
+		return errors.Errorf("App Store Connect returned %s", response.Status)
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+// download saves the file at a (pre-signed) URL to a path
+func (c *AppStoreConnectClient) download(fileUrl, path string) error {
+	request, err := http.NewRequest("GET", fileUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.HttpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("error downloading dSYMs from App Store Connect: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("Downloading dSYMs from App Store Connect returned %s", response.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, response.Body); err != nil {
+		return fmt.Errorf("error downloading dSYMs from App Store Connect: %w", err)
+	}
+
+	return nil
+}
+
+// token creates a signed ES256 JSON web token for the App Store Connect API
+func (c *AppStoreConnectClient) token(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": c.keyId, "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": c.issuerId,
+		"iat": now.Unix(),
+		"exp": now.Add(appStoreConnectTokenLifetime).Unix(),
+		"aud": "appstoreconnect-v1",
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, c.privateKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing App Store Connect token: %w", err)
+	}
+
+	// JWS signatures are the fixed size big-endian r and s values, rather than ASN.1
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	log.AddSecret(token)
+
+	return token, nil
+}
+
+// parseAppStoreConnectPrivateKey parses a PEM encoded P-256 private key, as downloaded from App Store Connect
+func parseAppStoreConnectPrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("The App Store Connect private key is not PEM encoded")
+	}
+
+	if block.Type == "EC PRIVATE KEY" {
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Errorf("Unable to parse the App Store Connect private key: %s", err)
+	}
+
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("The App Store Connect private key is not an EC key")
+	}
+
+	return privateKey, nil
+}
@@ -0,0 +1,134 @@
+package ios
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// ArchiveInfo contains the location of the dSYMs and the app details found in an .xcarchive or .ipa
+type ArchiveInfo struct {
+	DsymPath  string
+	PlistData *PlistData
+	// TempDir is the directory an .ipa was extracted to, which should be removed once the dSYMs are uploaded
+	TempDir string
+}
+
+// IsXcarchive checks if a path is an .xcarchive bundle
+func IsXcarchive(path string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Clean(path)), ".xcarchive") && utils.IsDir(path)
+}
+
+// IsIpa checks if a path is an .ipa file
+func IsIpa(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".ipa") && utils.FileExists(path) && !utils.IsDir(path)
+}
+
+// ReadXcarchive finds the dSYMs in an .xcarchive and reads the app's version, bundle version and API key
+func ReadXcarchive(path string, plutilFallback bool) (*ArchiveInfo, error) {
+	data, err := os.ReadFile(filepath.Join(path, "Info.plist"))
+	if err != nil {
+		return nil, errors.Errorf("Unable to read the Info.plist of %s: %s", path, err)
+	}
+
+	value, err := DecodePlist(data)
+	if err != nil {
+		return nil, errors.Errorf("Unable to read the Info.plist of %s: %s", path, err)
+	}
+
+	root, _ := value.(map[string]interface{})
+	properties, _ := root["ApplicationProperties"].(map[string]interface{})
+
+	plistData := &PlistData{
+		VersionName:   plistString(properties["CFBundleShortVersionString"]),
+		BundleVersion: plistString(properties["CFBundleVersion"]),
+	}
+
+	// The API key is only in the app's own Info.plist, which is kept in the archive's Products directory
+	if applicationPath := plistString(properties["ApplicationPath"]); applicationPath != "" {
+		mergeAppPlistData(plistData, filepath.Join(path, "Products", applicationPath), plutilFallback)
+	}
+
+	dsymPath := filepath.Join(path, "dSYMs")
+	if !utils.IsDir(dsymPath) {
+		return nil, errors.Errorf("No dSYMs directory found in %s, make sure the archive was built with DEBUG_INFORMATION_FORMAT set to dwarf-with-dsym", path)
+	}
+
+	return &ArchiveInfo{DsymPath: dsymPath, PlistData: plistData}, nil
+}
+
+// ReadIpa extracts an .ipa, reads the app's version, bundle version and API key and finds its dSYMs, either within
+// the .ipa or in an <App>.app.dSYM.zip alongside it as exported by Xcode and fastlane
+func ReadIpa(path string, plutilFallback bool) (*ArchiveInfo, error) {
+	fileName := filepath.Base(path)
+	log.Info("Attempting to unzip " + fileName + " before proceeding to upload")
+
+	tempDir, err := utils.ExtractFile(path, "ipa")
+	if err != nil {
+		return nil, errors.New("Could not unzip " + fileName + " to a temporary directory")
+	}
+
+	archiveInfo := &ArchiveInfo{PlistData: &PlistData{}, TempDir: tempDir}
+
+	apps, _ := filepath.Glob(filepath.Join(tempDir, "Payload", "*.app"))
+	if len(apps) == 0 {
+		return archiveInfo, errors.Errorf("%s does not contain an app in its Payload directory", fileName)
+	}
+
+	appPath := apps[0]
+	mergeAppPlistData(archiveInfo.PlistData, appPath, plutilFallback)
+
+	if len(findDsyms(tempDir)) > 0 {
+		archiveInfo.DsymPath = tempDir
+		return archiveInfo, nil
+	}
+
+	candidates := []string{
+		filepath.Join(filepath.Dir(path), filepath.Base(appPath)+".dSYM.zip"),
+		strings.TrimSuffix(path, filepath.Ext(path)) + ".app.dSYM.zip",
+	}
+
+	for _, candidate := range candidates {
+		if utils.FileExists(candidate) {
+			log.Info("Using dSYMs from " + candidate + " for " + fileName)
+			archiveInfo.DsymPath = candidate
+			return archiveInfo, nil
+		}
+	}
+
+	return archiveInfo, errors.Errorf("No dSYMs found in %s or alongside it, please upload the dSYMs from the .xcarchive instead", fileName)
+}
+
+// mergeAppPlistData fills in any missing details from the Info.plist of an .app bundle
+func mergeAppPlistData(plistData *PlistData, appPath string, plutilFallback bool) {
+	for _, plistPath := range []string{filepath.Join(appPath, "Info.plist"), filepath.Join(appPath, "Contents", "Info.plist")} {
+		if !utils.FileExists(plistPath) {
+			continue
+		}
+
+		appPlistData, err := GetPlistData(plistPath, plutilFallback)
+		if err != nil {
+			log.Warn(err.Error())
+			return
+		}
+
+		if plistData.VersionName == "" {
+			plistData.VersionName = appPlistData.VersionName
+		}
+
+		if plistData.BundleVersion == "" {
+			plistData.BundleVersion = appPlistData.BundleVersion
+		}
+
+		if plistData.BugsnagProjectDetails.ApiKey == "" {
+			plistData.BugsnagProjectDetails.ApiKey = appPlistData.BugsnagProjectDetails.ApiKey
+		}
+
+		return
+	}
+}
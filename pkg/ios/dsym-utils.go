@@ -2,7 +2,6 @@ package ios
 
 import (
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -55,12 +54,8 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool) ([]*
 
 	}
 
-	// If we have found dSYMs, use dwarfdump to get the UUID etc for each dSYM
+	// If we have found dSYMs, read the UUID etc for each dSYM from its Mach-O headers
 	if len(dsymLocations) > 0 {
-		if !isDwarfDumpInstalled() {
-			return nil, tempDir, errors.New("Unable to locate dwarfdump on this system.")
-		}
-
 		for _, dsymLocation := range dsymLocations {
 			filesFound, err := os.ReadDir(dsymLocation)
 
@@ -81,6 +76,7 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool) ([]*
 					if len(info) == 0 {
 						if ignoreMissingDwarf {
 							log.Info(fileInfo.Name() + " is not a valid DWARF file, skipping")
+							log.RecordFile(log.FileEvent{File: filepath.Join(dsymLocation, file.Name()), Status: log.StatusSkipped})
 						} else {
 							return nil, tempDir, errors.New(fileInfo.Name() + " is not a valid DWARF file")
 						}
@@ -89,6 +85,7 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool) ([]*
 				} else {
 					if ignoreEmptyDsym {
 						log.Info(file.Name() + " is empty, skipping")
+						log.RecordFile(log.FileEvent{File: filepath.Join(dsymLocation, file.Name()), Status: log.StatusSkipped})
 					} else {
 						return nil, tempDir, errors.New(file.Name() + " is empty")
 					}
@@ -100,41 +97,18 @@ func FindDsymsInPath(path string, ignoreEmptyDsym, ignoreMissingDwarf bool) ([]*
 	return dwarfInfo, tempDir, nil
 }
 
-// isDwarfDumpInstalled checks if dwarfdump is installed by checking if there is a path returned for it
-func isDwarfDumpInstalled() bool {
-	return utils.LocationOf(utils.DWARFDUMP) != ""
-}
-
-// getDwarfFileInfo parses dwarfdump output to easier to manage/parsable DwarfInfo structs
+// getDwarfFileInfo reads the UUID and architecture of each slice of a dSYM's DWARF file into DwarfInfo structs
 func getDwarfFileInfo(path, fileName string) []*DwarfInfo {
-	var dwarfInfo []*DwarfInfo
+	fileName = strings.TrimSuffix(fileName, ".zip")
 
-	cmd := exec.Command(utils.DWARFDUMP, "-u", strings.TrimSuffix(fileName, ".zip"))
-	cmd.Dir = path
-
-	output, _ := cmd.Output()
-	if len(output) > 0 {
-		outputStr := string(output)
-
-		outputStr = strings.TrimSuffix(outputStr, "\n")
-		outputStr = strings.ReplaceAll(outputStr, "(", "")
-		outputStr = strings.ReplaceAll(outputStr, ")", "")
-
-		outputSlice := strings.Split(outputStr, "\n")
-
-		for _, str := range outputSlice {
-			if strings.Contains(str, "UUID: ") {
-				rawDwarfInfo := strings.Split(str, " ")
-				if len(rawDwarfInfo) == 4 {
-					dwarf := &DwarfInfo{}
-					dwarf.UUID = rawDwarfInfo[1]
-					dwarf.Arch = rawDwarfInfo[2]
-					dwarf.Name = rawDwarfInfo[3]
-					dwarf.Location = path
-					dwarfInfo = append(dwarfInfo, dwarf)
-				}
-			}
-		}
+	dwarfInfo, err := GetMachOUuids(filepath.Join(path, fileName))
+	if err != nil {
+		return nil
+	}
+
+	for _, dwarf := range dwarfInfo {
+		dwarf.Name = fileName
+		dwarf.Location = path
 	}
 
 	return dwarfInfo
@@ -0,0 +1,132 @@
+package ios
+
+import (
+	"debug/macho"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// loadCmdUuid is the LC_UUID load command, which debug/macho doesn't decode
+const loadCmdUuid macho.LoadCmd = 0x1b
+
+// CPU types and subtypes from <mach/machine.h> that aren't defined by debug/macho
+const (
+	cpuArm6432       macho.Cpu = 0x0200000c
+	cpuSubtypeMask             = 0xff000000
+	cpuSubtypeArm64e           = 2
+	cpuSubtypeArmV6            = 6
+	cpuSubtypeArmV7            = 9
+	cpuSubtypeArmV7F           = 10
+	cpuSubtypeArmV7S           = 11
+	cpuSubtypeArmV7K           = 12
+	cpuSubtypeX8664H           = 8
+)
+
+// GetMachOUuids reads the UUID and architecture of every slice in a Mach-O file, including fat/universal binaries
+func GetMachOUuids(path string) ([]*DwarfInfo, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return GetMachOUuidsFromReader(file)
+}
+
+// GetMachOUuidsFromReader reads the UUID and architecture of every slice in Mach-O data, such as a file within a zip
+func GetMachOUuidsFromReader(reader io.ReaderAt) ([]*DwarfInfo, error) {
+	fatFile, err := macho.NewFatFile(reader)
+
+	if err == nil {
+		var dwarfInfo []*DwarfInfo
+
+		for _, arch := range fatFile.Arches {
+			uuid := getMachOUuid(arch.File)
+			if uuid != "" {
+				dwarfInfo = append(dwarfInfo, &DwarfInfo{UUID: uuid, Arch: getMachOArch(arch.Cpu, arch.SubCpu)})
+			}
+		}
+
+		return dwarfInfo, nil
+	}
+
+	if err != macho.ErrNotFat {
+		return nil, err
+	}
+
+	file, err := macho.NewFile(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	uuid := getMachOUuid(file)
+	if uuid == "" {
+		return nil, nil
+	}
+
+	return []*DwarfInfo{{UUID: uuid, Arch: getMachOArch(file.Cpu, file.SubCpu)}}, nil
+}
+
+// getMachOUuid returns the UUID from the LC_UUID load command of a Mach-O file, formatted as dwarfdump does
+func getMachOUuid(file *macho.File) string {
+	for _, load := range file.Loads {
+		raw := load.Raw()
+
+		if len(raw) < 24 || macho.LoadCmd(file.ByteOrder.Uint32(raw[0:4])) != loadCmdUuid {
+			continue
+		}
+
+		uuid := raw[8:24]
+
+		return strings.ToUpper(fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]))
+	}
+
+	return ""
+}
+
+// getMachOArch returns the architecture name used by Xcode tools for a CPU type and subtype
+func getMachOArch(cpu macho.Cpu, subCpu uint32) string {
+	subCpu = subCpu &^ cpuSubtypeMask
+
+	switch cpu {
+	case macho.CpuArm64:
+		if subCpu == cpuSubtypeArm64e {
+			return "arm64e"
+		}
+		return "arm64"
+	case cpuArm6432:
+		return "arm64_32"
+	case macho.CpuArm:
+		switch subCpu {
+		case cpuSubtypeArmV6:
+			return "armv6"
+		case cpuSubtypeArmV7:
+			return "armv7"
+		case cpuSubtypeArmV7F:
+			return "armv7f"
+		case cpuSubtypeArmV7S:
+			return "armv7s"
+		case cpuSubtypeArmV7K:
+			return "armv7k"
+		}
+		return "arm"
+	case macho.CpuAmd64:
+		if subCpu == cpuSubtypeX8664H {
+			return "x86_64h"
+		}
+		return "x86_64"
+	case macho.Cpu386:
+		return "i386"
+	case macho.CpuPpc:
+		return "ppc"
+	case macho.CpuPpc64:
+		return "ppc64"
+	}
+
+	return fmt.Sprintf("unknown(%d,%d)", uint32(cpu), subCpu)
+}
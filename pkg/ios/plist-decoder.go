@@ -0,0 +1,719 @@
+package ios
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// plistEpoch is the reference date used by binary plists, 2001-01-01 00:00:00 UTC
+var plistEpoch = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// maxPlistDepth limits how deeply nested a plist can be, to guard against malformed or malicious files
+const maxPlistDepth = 512
+
+// DecodePlist decodes a property list in XML, binary (bplist00) or OpenStep format.
+//
+// Dictionaries are returned as map[string]interface{}, arrays as []interface{}, and the
+// other values as string, int64, uint64, float64, bool, time.Time or []byte.
+// OpenStep plists have no types other than strings, dictionaries, arrays and data.
+func DecodePlist(data []byte) (interface{}, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	if bytes.HasPrefix(data, []byte("bplist00")) {
+		return decodeBinaryPlist(data)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<!DOCTYPE")) || bytes.HasPrefix(trimmed, []byte("<plist")) {
+		return decodeXmlPlist(data)
+	}
+
+	return decodeOpenStepPlist(data)
+}
+
+// decodeXmlPlist decodes an XML property list
+func decodeXmlPlist(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("unable to find a value in the XML plist: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local == "plist" {
+				continue
+			}
+
+			return decodeXmlValue(decoder, start, 0)
+		}
+	}
+}
+
+// decodeXmlValue decodes the XML plist element that has just been started
+func decodeXmlValue(decoder *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxPlistDepth {
+		return nil, fmt.Errorf("plist is nested too deeply")
+	}
+
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		var key *string
+
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch token := token.(type) {
+			case xml.StartElement:
+				if token.Name.Local == "key" {
+					text, err := xmlText(decoder)
+					if err != nil {
+						return nil, err
+					}
+					key = &text
+					continue
+				}
+
+				if key == nil {
+					return nil, fmt.Errorf("found <%s> without a <key> in <dict>", token.Name.Local)
+				}
+
+				value, err := decodeXmlValue(decoder, token, depth+1)
+				if err != nil {
+					return nil, err
+				}
+
+				dict[*key] = value
+				key = nil
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+
+	case "array":
+		array := []interface{}{}
+
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			switch token := token.(type) {
+			case xml.StartElement:
+				value, err := decodeXmlValue(decoder, token, depth+1)
+				if err != nil {
+					return nil, err
+				}
+
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+
+	case "true", "false":
+		err := decoder.Skip()
+		return start.Name.Local == "true", err
+	}
+
+	text, err := xmlText(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+
+	case "integer":
+		return parsePlistInteger(strings.TrimSpace(text))
+
+	case "real":
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid <real> value %q", text)
+		}
+		return value, nil
+
+	case "date":
+		value, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid <date> value %q", text)
+		}
+		return value, nil
+
+	case "data":
+		value, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid <data> value: %w", err)
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unknown plist element <%s>", start.Name.Local)
+}
+
+// xmlText reads the text content of the current element, up to and including its end tag
+func xmlText(decoder *xml.Decoder) (string, error) {
+	var text strings.Builder
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch token := token.(type) {
+		case xml.CharData:
+			text.Write(token)
+		case xml.StartElement:
+			return "", fmt.Errorf("unexpected <%s> in a plist value", token.Name.Local)
+		case xml.EndElement:
+			return text.String(), nil
+		}
+	}
+}
+
+// parsePlistInteger parses a plist integer, which can be any signed or unsigned 64-bit value
+func parsePlistInteger(text string) (interface{}, error) {
+	if value, err := strconv.ParseInt(text, 0, 64); err == nil {
+		return value, nil
+	}
+
+	if value, err := strconv.ParseUint(text, 0, 64); err == nil {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("invalid <integer> value %q", text)
+}
+
+// binaryPlist holds the state needed to decode the objects of a binary property list
+type binaryPlist struct {
+	data          []byte
+	offsets       []uint64
+	objectRefSize int
+	decoding      map[uint64]bool
+}
+
+// decodeBinaryPlist decodes a binary (bplist00) property list
+func decodeBinaryPlist(data []byte) (interface{}, error) {
+	if len(data) < 8+32 {
+		return nil, fmt.Errorf("binary plist is too short")
+	}
+
+	trailer := data[len(data)-32:]
+	offsetIntSize := int(trailer[6])
+	objectRefSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:16])
+	topObject := binary.BigEndian.Uint64(trailer[16:24])
+	offsetTableOffset := binary.BigEndian.Uint64(trailer[24:32])
+
+	if offsetIntSize < 1 || offsetIntSize > 8 || objectRefSize < 1 || objectRefSize > 8 {
+		return nil, fmt.Errorf("binary plist has an invalid trailer")
+	}
+
+	tableEnd := uint64(len(data) - 32)
+	if numObjects == 0 || offsetTableOffset >= tableEnd || numObjects > (tableEnd-offsetTableOffset)/uint64(offsetIntSize) || topObject >= numObjects {
+		return nil, fmt.Errorf("binary plist has an invalid offset table")
+	}
+
+	plist := &binaryPlist{
+		data:          data,
+		offsets:       make([]uint64, numObjects),
+		objectRefSize: objectRefSize,
+		decoding:      make(map[uint64]bool),
+	}
+
+	for i := uint64(0); i < numObjects; i++ {
+		start := offsetTableOffset + i*uint64(offsetIntSize)
+		plist.offsets[i] = readSizedUint(data[start : start+uint64(offsetIntSize)])
+	}
+
+	return plist.object(topObject, 0)
+}
+
+// object decodes the object with the given reference
+func (p *binaryPlist) object(ref uint64, depth int) (interface{}, error) {
+	if ref >= uint64(len(p.offsets)) {
+		return nil, fmt.Errorf("binary plist object reference %d is out of range", ref)
+	}
+
+	if depth > maxPlistDepth || p.decoding[ref] {
+		return nil, fmt.Errorf("binary plist contains a cycle or is nested too deeply")
+	}
+
+	offset := p.offsets[ref]
+	if offset >= uint64(len(p.data)-32) {
+		return nil, fmt.Errorf("binary plist object offset %d is out of range", offset)
+	}
+
+	marker := p.data[offset]
+	objectType, info := marker>>4, marker&0x0f
+	offset++
+
+	switch objectType {
+	case 0x0:
+		switch info {
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+		return nil, nil
+
+	case 0x1:
+		size := uint64(1) << info
+		bytes, err := p.read(offset, size)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > 8 {
+			// 128-bit integers are only used for values that don't fit in 64 bits signed, keep the low 64 bits
+			return readSizedUint(bytes[size-8:]), nil
+		}
+
+		// Integers of 1, 2 or 4 bytes are unsigned and 8 byte integers are signed, so all fit in an int64
+		return int64(readSizedUint(bytes)), nil
+
+	case 0x2:
+		size := uint64(1) << info
+		bytes, err := p.read(offset, size)
+		if err != nil {
+			return nil, err
+		}
+
+		switch size {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(bytes))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(bytes)), nil
+		}
+		return nil, fmt.Errorf("binary plist real has an unsupported size of %d bytes", size)
+
+	case 0x3:
+		bytes, err := p.read(offset, 8)
+		if err != nil {
+			return nil, err
+		}
+
+		seconds := math.Float64frombits(binary.BigEndian.Uint64(bytes))
+		return plistEpoch.Add(time.Duration(seconds * float64(time.Second))), nil
+
+	case 0x4, 0x5, 0x6:
+		length, offset, err := p.length(info, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		if objectType == 0x6 {
+			bytes, err := p.read(offset, length*2)
+			if err != nil {
+				return nil, err
+			}
+
+			units := make([]uint16, length)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(bytes[i*2:])
+			}
+			return string(utf16.Decode(units)), nil
+		}
+
+		bytes, err := p.read(offset, length)
+		if err != nil {
+			return nil, err
+		}
+
+		if objectType == 0x4 {
+			return append([]byte(nil), bytes...), nil
+		}
+		return string(bytes), nil
+
+	case 0x8:
+		bytes, err := p.read(offset, uint64(info)+1)
+		if err != nil {
+			return nil, err
+		}
+		return readSizedUint(bytes), nil
+
+	case 0xa, 0xc, 0xd:
+		length, offset, err := p.length(info, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		count := length
+		if objectType == 0xd {
+			count = length * 2
+		}
+
+		refs, err := p.read(offset, count*uint64(p.objectRefSize))
+		if err != nil {
+			return nil, err
+		}
+
+		p.decoding[ref] = true
+		defer delete(p.decoding, ref)
+
+		values := make([]interface{}, count)
+		for i := range values {
+			start := i * p.objectRefSize
+			values[i], err = p.object(readSizedUint(refs[start:start+p.objectRefSize]), depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if objectType != 0xd {
+			return values, nil
+		}
+
+		dict := make(map[string]interface{}, length)
+		for i := uint64(0); i < length; i++ {
+			key, ok := values[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("binary plist dictionary has a key that is not a string")
+			}
+			dict[key] = values[length+i]
+		}
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("binary plist has an unknown object type 0x%x", marker)
+}
+
+// length reads the length of a data, string or collection object, which is either stored in
+// the marker or, when the marker is 0xf, in a following integer object
+func (p *binaryPlist) length(info byte, offset uint64) (uint64, uint64, error) {
+	if info != 0xf {
+		return uint64(info), offset, nil
+	}
+
+	marker, err := p.read(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if marker[0]>>4 != 0x1 {
+		return 0, 0, fmt.Errorf("binary plist has an invalid length")
+	}
+
+	size := uint64(1) << (marker[0] & 0x0f)
+	if size > 8 {
+		return 0, 0, fmt.Errorf("binary plist has an invalid length")
+	}
+
+	bytes, err := p.read(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Every element takes at least one byte, so a length longer than the rest of the file is malformed and is rejected
+	// before it is used to size reads or allocations
+	length := readSizedUint(bytes)
+	offset += 1 + size
+	if length > uint64(len(p.data)-32)-offset {
+		return 0, 0, fmt.Errorf("binary plist object extends beyond the end of the file")
+	}
+
+	return length, offset, nil
+}
+
+// read returns the given number of bytes from the object data, checking that they are in range
+func (p *binaryPlist) read(offset uint64, size uint64) ([]byte, error) {
+	end := offset + size
+	if end < offset || end > uint64(len(p.data)-32) {
+		return nil, fmt.Errorf("binary plist object extends beyond the end of the file")
+	}
+
+	return p.data[offset:end], nil
+}
+
+// readSizedUint reads a big-endian unsigned integer of up to 8 bytes
+func readSizedUint(bytes []byte) uint64 {
+	var value uint64
+	for _, b := range bytes {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// openStepParser holds the state needed to decode an OpenStep (old-style ASCII) property list
+type openStepParser struct {
+	data []byte
+	pos  int
+}
+
+// decodeOpenStepPlist decodes an OpenStep property list, as used by .pbxproj and .strings files
+func decodeOpenStepPlist(data []byte) (interface{}, error) {
+	parser := &openStepParser{data: data}
+
+	value, err := parser.value(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.skipWhitespace(); parser.pos < len(parser.data) {
+		return nil, parser.errorf("unexpected %q after the end of the plist", parser.data[parser.pos])
+	}
+
+	return value, nil
+}
+
+// value parses the value starting at the current position
+func (p *openStepParser) value(depth int) (interface{}, error) {
+	if depth > maxPlistDepth {
+		return nil, p.errorf("plist is nested too deeply")
+	}
+
+	p.skipWhitespace()
+
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of plist")
+	}
+
+	switch p.data[p.pos] {
+	case '{':
+		p.pos++
+		dict := make(map[string]interface{})
+
+		for {
+			p.skipWhitespace()
+			if p.peek() == '}' {
+				p.pos++
+				return dict, nil
+			}
+
+			key, err := p.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, p.errorf("dictionary key is not a string")
+			}
+
+			if err := p.expect('='); err != nil {
+				return nil, err
+			}
+
+			value, err := p.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expect(';'); err != nil {
+				return nil, err
+			}
+
+			dict[keyString] = value
+		}
+
+	case '(':
+		p.pos++
+		array := []interface{}{}
+
+		for {
+			p.skipWhitespace()
+			if p.peek() == ')' {
+				p.pos++
+				return array, nil
+			}
+
+			value, err := p.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+
+			p.skipWhitespace()
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != ')' {
+				return nil, p.errorf("expected ',' or ')' in array")
+			}
+		}
+
+	case '<':
+		p.pos++
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, p.errorf("unterminated data value")
+		}
+
+		hexString := strings.Join(strings.Fields(string(p.data[p.pos:p.pos+end])), "")
+		p.pos += end + 1
+
+		value, err := hex.DecodeString(hexString)
+		if err != nil {
+			return nil, p.errorf("invalid data value: %s", err)
+		}
+		return value, nil
+
+	case '"', '\'':
+		return p.quotedString()
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && isOpenStepUnquotedChar(p.data[p.pos]) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, p.errorf("unexpected %q", p.data[p.pos])
+	}
+
+	return string(p.data[start:p.pos]), nil
+}
+
+// quotedString parses a quoted string, handling the escape sequences allowed by OpenStep plists
+func (p *openStepParser) quotedString() (string, error) {
+	quote := p.data[p.pos]
+	p.pos++
+
+	var text strings.Builder
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		if c == quote {
+			return text.String(), nil
+		}
+
+		if c != '\\' {
+			text.WriteByte(c)
+			continue
+		}
+
+		if p.pos >= len(p.data) {
+			break
+		}
+
+		c = p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case 'a':
+			text.WriteByte('\a')
+		case 'b':
+			text.WriteByte('\b')
+		case 'f':
+			text.WriteByte('\f')
+		case 'n':
+			text.WriteByte('\n')
+		case 'r':
+			text.WriteByte('\r')
+		case 't':
+			text.WriteByte('\t')
+		case 'v':
+			text.WriteByte('\v')
+		case 'U', 'u':
+			end := p.pos
+			for end < len(p.data) && end < p.pos+4 && isHexDigit(p.data[end]) {
+				end++
+			}
+
+			code, err := strconv.ParseUint(string(p.data[p.pos:end]), 16, 32)
+			if err != nil {
+				return "", p.errorf("invalid unicode escape")
+			}
+
+			text.WriteRune(rune(code))
+			p.pos = end
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := p.pos - 1
+			for end < len(p.data) && end < p.pos+2 && p.data[end] >= '0' && p.data[end] <= '7' {
+				end++
+			}
+
+			code, _ := strconv.ParseUint(string(p.data[p.pos-1:end]), 8, 8)
+			text.WriteByte(byte(code))
+			p.pos = end
+		default:
+			text.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+// skipWhitespace skips whitespace and // or /* */ comments
+func (p *openStepParser) skipWhitespace() {
+	for p.pos < len(p.data) {
+		switch {
+		case p.data[p.pos] == ' ' || p.data[p.pos] == '\t' || p.data[p.pos] == '\n' || p.data[p.pos] == '\r':
+			p.pos++
+		case bytes.HasPrefix(p.data[p.pos:], []byte("//")):
+			end := bytes.IndexByte(p.data[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.data)
+			} else {
+				p.pos += end + 1
+			}
+		case bytes.HasPrefix(p.data[p.pos:], []byte("/*")):
+			end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.data)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+// expect skips whitespace and then consumes the given character
+func (p *openStepParser) expect(c byte) error {
+	p.skipWhitespace()
+
+	if p.peek() != c {
+		return p.errorf("expected '%c'", c)
+	}
+
+	p.pos++
+	return nil
+}
+
+// peek returns the character at the current position, or 0 at the end of the plist
+func (p *openStepParser) peek() byte {
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+// errorf returns an error describing a problem at the current line of the plist
+func (p *openStepParser) errorf(format string, args ...interface{}) error {
+	line := bytes.Count(p.data[:p.pos], []byte("\n")) + 1
+	return fmt.Errorf("invalid plist at line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// isOpenStepUnquotedChar checks whether a character can appear in an unquoted OpenStep string
+func isOpenStepUnquotedChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("_$+/:.-", c) >= 0
+}
+
+// isHexDigit checks whether a character is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
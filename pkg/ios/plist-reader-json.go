@@ -2,10 +2,13 @@ package ios
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
 	"os/exec"
 
 	"github.com/pkg/errors"
 
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 )
 
@@ -20,27 +23,83 @@ type bugsnagProjectDetails struct {
 	ApiKey string `json:"apiKey"`
 }
 
-// GetPlistData returns the relevant content of a plist file as a PlistData struct
-func GetPlistData(plistFilePath string) (*PlistData, error) {
-	var plistData *PlistData
-	var cmd *exec.Cmd
-
-	if isPlutilInstalled() {
-		cmd = exec.Command(utils.LocationOf(utils.PLUTIL), "-convert", "json", "-o", "-", plistFilePath)
+// GetPlistData returns the relevant content of a plist file as a PlistData struct.
+// If plutilFallback is set, plutil is used to read plists that can't be decoded natively.
+func GetPlistData(plistFilePath string, plutilFallback bool) (*PlistData, error) {
+	data, err := os.ReadFile(plistFilePath)
+	if err != nil {
+		return nil, err
+	}
 
-		output, err := cmd.Output()
-		if err != nil {
-			return nil, err
+	value, err := DecodePlist(data)
+	if err == nil {
+		var plistData *PlistData
+		plistData, err = newPlistData(value)
+		if err == nil {
+			return plistData, nil
 		}
+	}
 
-		err = json.Unmarshal(output, &plistData)
-		if err != nil {
-			return nil, err
-		}
-	} else {
+	if !plutilFallback {
+		return nil, fmt.Errorf("unable to read %s: %w", plistFilePath, err)
+	}
+
+	log.Warn("Unable to read " + plistFilePath + " (" + err.Error() + "), falling back to plutil")
+
+	return getPlistDataUsingPlutil(plistFilePath)
+}
+
+// newPlistData extracts the relevant content from a decoded plist
+func newPlistData(value interface{}) (*PlistData, error) {
+	root, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the plist does not contain a dictionary")
+	}
+
+	plistData := &PlistData{
+		VersionName:   plistString(root["CFBundleShortVersionString"]),
+		BundleVersion: plistString(root["CFBundleVersion"]),
+	}
+
+	if bugsnag, ok := root["bugsnag"].(map[string]interface{}); ok {
+		plistData.BugsnagProjectDetails.ApiKey = plistString(bugsnag["apiKey"])
+	}
+
+	return plistData, nil
+}
+
+// plistString returns a plist value as a string, or an empty string if it isn't a string or number
+func plistString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case int64, uint64, float64:
+		return fmt.Sprint(value)
+	}
+
+	return ""
+}
+
+// getPlistDataUsingPlutil converts a plist to JSON with plutil and returns its relevant content
+func getPlistDataUsingPlutil(plistFilePath string) (*PlistData, error) {
+	var plistData *PlistData
+
+	if !isPlutilInstalled() {
 		return nil, errors.Errorf("Unable to locate plutil on this system.")
 	}
 
+	cmd := exec.Command(utils.LocationOf(utils.PLUTIL), "-convert", "json", "-o", "-", plistFilePath)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(output, &plistData)
+	if err != nil {
+		return nil, err
+	}
+
 	return plistData, nil
 }
 
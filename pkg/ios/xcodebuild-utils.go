@@ -9,6 +9,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 )
 
@@ -19,6 +20,11 @@ type XcodeBuildSettings struct {
 	BuiltProductsDir      string `mapstructure:"BUILT_PRODUCTS_DIR"`
 	DsymName              string `mapstructure:"DWARF_DSYM_FILE_NAME"`
 	ProjectTempRoot       string `mapstructure:"PROJECT_TEMP_ROOT"`
+	InfoPlistFile         string `mapstructure:"INFOPLIST_FILE"`
+	ProductName           string `mapstructure:"PRODUCT_NAME"`
+	FullProductName       string `mapstructure:"FULL_PRODUCT_NAME"`
+	Configuration         string `mapstructure:"CONFIGURATION"`
+	SrcRoot               string `mapstructure:"SRCROOT"`
 }
 
 // GetDefaultScheme checks if a scheme is in a given path or checks current directory if path is empty
@@ -47,8 +53,23 @@ func IsSchemeInPath(path, schemeToFind string) (bool, error) {
 	return false, errors.Errorf("Unable to locate scheme '%s' in location: '%s'", schemeToFind, path)
 }
 
-// getXcodeSchemes parses the xcodebuild output for a given path to return a slice of schemes
+// getXcodeSchemes returns the schemes for a given path, parsing the Xcode project files and falling back to xcodebuild
 func getXcodeSchemes(path string) []string {
+	schemes, err := FindXcodeSchemes(path)
+	if err == nil && len(schemes) > 0 {
+		schemeNames := make([]string, len(schemes))
+		for i, scheme := range schemes {
+			schemeNames[i] = scheme.Name
+		}
+
+		return schemeNames
+	}
+
+	return getXcodeSchemesUsingXcodebuild(path)
+}
+
+// getXcodeSchemesUsingXcodebuild parses the xcodebuild output for a given path to return a slice of schemes
+func getXcodeSchemesUsingXcodebuild(path string) []string {
 	var cmd *exec.Cmd
 
 	if isXcodebuildInstalled() {
@@ -103,8 +124,31 @@ func GetXcodeBuildSettings(path, schemeName string) (*XcodeBuildSettings, error)
 	return &buildSettings, nil
 }
 
-// getXcodeBuildSettings parses the xcodebuild output for a given path and scheme to return a map of all build settings
+// getXcodeBuildSettings returns a map of all build settings for a given path and scheme, parsing the Xcode project
+// files and falling back to xcodebuild
 func getXcodeBuildSettings(path, schemeName string) (*map[string]*string, error) {
+	nativeBuildSettings, err := getNativeXcodeBuildSettings(path, schemeName)
+	if err == nil {
+		buildSettingsMap := make(map[string]*string, len(nativeBuildSettings))
+		for key, value := range nativeBuildSettings {
+			value := value
+			buildSettingsMap[key] = &value
+		}
+
+		return &buildSettingsMap, nil
+	}
+
+	if !isXcodebuildInstalled() {
+		return nil, err
+	}
+
+	log.Info("Unable to resolve build settings from the Xcode project (" + err.Error() + "), falling back to xcodebuild")
+
+	return getXcodeBuildSettingsUsingXcodebuild(path, schemeName)
+}
+
+// getXcodeBuildSettingsUsingXcodebuild parses the xcodebuild output for a given path and scheme to return a map of all build settings
+func getXcodeBuildSettingsUsingXcodebuild(path, schemeName string) (*map[string]*string, error) {
 	var cmd *exec.Cmd
 
 	if isXcodebuildInstalled() {
@@ -144,11 +188,16 @@ func getXcodeBuildSettings(path, schemeName string) (*map[string]*string, error)
 	return &buildSettingsMap, nil
 }
 
+// IsPathAnXcodeProjectOrWorkspace checks if a path is, or is a directory containing, an Xcode project or workspace
 func IsPathAnXcodeProjectOrWorkspace(path string) bool {
 	if strings.HasSuffix(path, ".xcodeproj") || strings.HasSuffix(path, ".xcworkspace") {
 		return true
 	}
 
+	if FindXcodeProjOrWorkspace(path) != "" {
+		return true
+	}
+
 	var err error
 	if isXcodebuildInstalled() {
 		cmd := exec.Command(utils.LocationOf(utils.XCODEBUILD), "-list")
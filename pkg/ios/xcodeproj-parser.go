@@ -0,0 +1,485 @@
+package ios
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// XcodeProject is a parsed project.pbxproj file
+type XcodeProject struct {
+	Path    string
+	objects map[string]interface{}
+	root    map[string]interface{}
+}
+
+// maxSettingDepth limits how deeply build setting references are expanded, to guard against cycles
+const maxSettingDepth = 32
+
+// Matches $(NAME), ${NAME} and $NAME build setting references, with optional :modifiers inside brackets
+var settingReferencePattern = regexp.MustCompile(`\$\(([A-Za-z0-9_]+)((?::[^)]*)?)\)|\$\{([A-Za-z0-9_]+)((?::[^}]*)?)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Matches a `KEY = VALUE` assignment in an xcconfig file
+var xcconfigAssignmentPattern = regexp.MustCompile(`^([A-Za-z0-9_]+)(\[[^\]]*\])*\s*=\s*(.*?);?$`)
+
+// Matches an #include or #include? directive in an xcconfig file
+var xcconfigIncludePattern = regexp.MustCompile(`^#include\??\s+"([^"]+)"`)
+
+// wrapperExtensions are the bundle extensions for each product type that Xcode builds
+var wrapperExtensions = map[string]string{
+	"com.apple.product-type.application":                           "app",
+	"com.apple.product-type.application.watchapp":                  "app",
+	"com.apple.product-type.application.watchapp2":                 "app",
+	"com.apple.product-type.application.watchapp2-container":       "app",
+	"com.apple.product-type.application.on-demand-install-capable": "app",
+	"com.apple.product-type.framework":                             "framework",
+	"com.apple.product-type.framework.static":                      "framework",
+	"com.apple.product-type.app-extension":                         "appex",
+	"com.apple.product-type.app-extension.messages":                "appex",
+	"com.apple.product-type.extensionkit-extension":                "appex",
+	"com.apple.product-type.watchkit-extension":                    "appex",
+	"com.apple.product-type.watchkit2-extension":                   "appex",
+	"com.apple.product-type.tv-app-extension":                      "appex",
+	"com.apple.product-type.bundle":                                "bundle",
+	"com.apple.product-type.bundle.unit-test":                      "xctest",
+	"com.apple.product-type.bundle.ui-testing":                     "xctest",
+	"com.apple.product-type.xpc-service":                           "xpc",
+	"com.apple.product-type.app-clip":                              "app",
+}
+
+// LoadXcodeProject reads and parses the project.pbxproj file of a .xcodeproj
+func LoadXcodeProject(path string) (*XcodeProject, error) {
+	data, err := os.ReadFile(filepath.Join(path, "project.pbxproj"))
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := DecodePlist(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	plist, _ := value.(map[string]interface{})
+	objects, _ := plist["objects"].(map[string]interface{})
+	if objects == nil {
+		return nil, errors.Errorf("%s does not contain any objects", path)
+	}
+
+	project := &XcodeProject{Path: path, objects: objects}
+	project.root = project.object(plist["rootObject"])
+	if project.root == nil {
+		return nil, errors.Errorf("%s does not contain a root project object", path)
+	}
+
+	return project, nil
+}
+
+// Name returns the name of the project, without the .xcodeproj extension
+func (p *XcodeProject) Name() string {
+	return strings.TrimSuffix(filepath.Base(p.Path), ".xcodeproj")
+}
+
+// Targets returns the names of the targets in the project, in the order they are defined
+func (p *XcodeProject) Targets() []string {
+	var names []string
+
+	for _, target := range p.objectList(p.root["targets"]) {
+		if name := plistString(target["name"]); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// DefaultConfiguration returns the configuration that xcodebuild uses when none is given, which is the project's
+// default configuration or otherwise Release
+func (p *XcodeProject) DefaultConfiguration() string {
+	configurationList := p.object(p.root["buildConfigurationList"])
+
+	if name := plistString(configurationList["defaultConfigurationName"]); name != "" {
+		return name
+	}
+
+	return "Release"
+}
+
+// BuildSettings resolves the build settings of a target for a configuration, as xcodebuild -showBuildSettings would.
+// containerPath is the workspace or project being built, which determines the DerivedData location.
+func (p *XcodeProject) BuildSettings(targetName, configuration, containerPath string) (map[string]string, error) {
+	target := p.target(targetName)
+	if target == nil {
+		return nil, errors.Errorf("Unable to locate target '%s' in %s", targetName, p.Path)
+	}
+
+	if configuration == "" {
+		configuration = p.DefaultConfiguration()
+	}
+
+	projectDir := filepath.Dir(p.Path)
+	derivedDataDir, err := getDerivedDataDir(containerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{
+		"ACTION":                  "build",
+		"CONFIGURATION":           configuration,
+		"PROJECT":                 p.Name(),
+		"PROJECT_NAME":            p.Name(),
+		"PROJECT_DIR":             projectDir,
+		"PROJECT_FILE_PATH":       p.Path,
+		"SRCROOT":                 projectDir,
+		"SOURCE_ROOT":             projectDir,
+		"TARGET_NAME":             targetName,
+		"TARGETNAME":              targetName,
+		"PRODUCT_NAME":            "$(TARGET_NAME)",
+		"SDKROOT":                 "macosx",
+		"SYMROOT":                 filepath.Join(derivedDataDir, "Build", "Products"),
+		"OBJROOT":                 filepath.Join(derivedDataDir, "Build", "Intermediates.noindex"),
+		"BUILD_DIR":               "$(SYMROOT)",
+		"BUILD_ROOT":              "$(SYMROOT)",
+		"PROJECT_TEMP_ROOT":       "$(OBJROOT)",
+		"CONFIGURATION_BUILD_DIR": "$(BUILD_DIR)/$(CONFIGURATION)$(EFFECTIVE_PLATFORM_NAME)",
+		"BUILT_PRODUCTS_DIR":      "$(CONFIGURATION_BUILD_DIR)",
+		"WRAPPER_SUFFIX":          "$(WRAPPER_EXTENSION:prefix=.)",
+		"WRAPPER_NAME":            "$(PRODUCT_NAME)$(WRAPPER_SUFFIX)",
+		"FULL_PRODUCT_NAME":       "$(WRAPPER_NAME)",
+		"DWARF_DSYM_FILE_NAME":    "$(FULL_PRODUCT_NAME).dSYM",
+		"DWARF_DSYM_FOLDER_PATH":  "$(CONFIGURATION_BUILD_DIR)",
+	}
+
+	if extension, ok := wrapperExtensions[plistString(target["productType"])]; ok {
+		settings["WRAPPER_EXTENSION"] = extension
+	}
+
+	p.applyConfiguration(settings, p.root["buildConfigurationList"], configuration)
+	p.applyConfiguration(settings, target["buildConfigurationList"], configuration)
+
+	platform := resolveBuildSetting(settings, "SDKROOT", 0)
+	platform = strings.TrimSuffix(filepath.Base(platform), ".sdk")
+	platform = strings.TrimRightFunc(platform, func(r rune) bool { return r == '.' || (r >= '0' && r <= '9') })
+	setBuildSettingDefault(settings, "PLATFORM_NAME", platform)
+
+	if platform == "macosx" {
+		setBuildSettingDefault(settings, "EFFECTIVE_PLATFORM_NAME", "")
+		setBuildSettingDefault(settings, "CONTENTS_FOLDER_PATH", "$(WRAPPER_NAME)/Contents")
+		if settings["WRAPPER_EXTENSION"] == "framework" {
+			setBuildSettingDefault(settings, "INFOPLIST_PATH", "$(WRAPPER_NAME)/Versions/A/Resources/Info.plist")
+		}
+	} else {
+		setBuildSettingDefault(settings, "EFFECTIVE_PLATFORM_NAME", "-"+platform)
+		setBuildSettingDefault(settings, "CONTENTS_FOLDER_PATH", "$(WRAPPER_NAME)")
+	}
+	setBuildSettingDefault(settings, "INFOPLIST_PATH", "$(CONTENTS_FOLDER_PATH)/Info.plist")
+
+	resolved := make(map[string]string, len(settings))
+	for key := range settings {
+		resolved[key] = resolveBuildSetting(settings, key, 0)
+	}
+
+	return resolved, nil
+}
+
+// target returns the target object with the given name
+func (p *XcodeProject) target(name string) map[string]interface{} {
+	for _, target := range p.objectList(p.root["targets"]) {
+		if plistString(target["name"]) == name {
+			return target
+		}
+	}
+
+	return nil
+}
+
+// applyConfiguration layers the xcconfig file and build settings of the named configuration onto settings
+func (p *XcodeProject) applyConfiguration(settings map[string]string, configurationListRef interface{}, configuration string) {
+	configurationList := p.object(configurationListRef)
+
+	for _, buildConfiguration := range p.objectList(configurationList["buildConfigurations"]) {
+		if plistString(buildConfiguration["name"]) != configuration {
+			continue
+		}
+
+		if xcconfigPath := p.filePath(buildConfiguration["baseConfigurationReference"]); xcconfigPath != "" {
+			applyXcconfig(settings, xcconfigPath, 0)
+		}
+
+		buildSettings, _ := buildConfiguration["buildSettings"].(map[string]interface{})
+
+		keys := make([]string, 0, len(buildSettings))
+		for key := range buildSettings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			// Conditional settings such as KEY[sdk=iphoneos*] depend on the destination, so are left to xcodebuild
+			if strings.Contains(key, "[") {
+				continue
+			}
+
+			setBuildSetting(settings, key, buildSettingString(buildSettings[key]))
+		}
+	}
+}
+
+// filePath returns the absolute path of a PBXFileReference, or an empty string if it can't be resolved
+func (p *XcodeProject) filePath(ref interface{}) string {
+	fileReference := p.object(ref)
+	path := plistString(fileReference["path"])
+	if path == "" {
+		return ""
+	}
+
+	switch plistString(fileReference["sourceTree"]) {
+	case "<absolute>":
+		return path
+	case "SOURCE_ROOT":
+		return filepath.Join(filepath.Dir(p.Path), path)
+	case "<group>":
+		return filepath.Join(p.groupPath(ref), path)
+	}
+
+	return ""
+}
+
+// groupPath returns the absolute directory that paths relative to the group containing an object are resolved against
+func (p *XcodeProject) groupPath(ref interface{}) string {
+	id := plistString(ref)
+
+	for groupId, value := range p.objects {
+		group, _ := value.(map[string]interface{})
+		if plistString(group["isa"]) != "PBXGroup" && plistString(group["isa"]) != "PBXVariantGroup" {
+			continue
+		}
+
+		for _, child := range plistArray(group["children"]) {
+			if plistString(child) != id {
+				continue
+			}
+
+			if groupId == plistString(p.root["mainGroup"]) {
+				return filepath.Dir(p.Path)
+			}
+
+			switch plistString(group["sourceTree"]) {
+			case "<absolute>":
+				return plistString(group["path"])
+			case "SOURCE_ROOT":
+				return filepath.Join(filepath.Dir(p.Path), plistString(group["path"]))
+			}
+
+			return filepath.Join(p.groupPath(groupId), plistString(group["path"]))
+		}
+	}
+
+	return filepath.Dir(p.Path)
+}
+
+// object returns the object with the given ID, or nil if there isn't one
+func (p *XcodeProject) object(ref interface{}) map[string]interface{} {
+	object, _ := p.objects[plistString(ref)].(map[string]interface{})
+	return object
+}
+
+// objectList returns the objects referenced by an array of IDs
+func (p *XcodeProject) objectList(refs interface{}) []map[string]interface{} {
+	var objects []map[string]interface{}
+
+	for _, ref := range plistArray(refs) {
+		if object := p.object(ref); object != nil {
+			objects = append(objects, object)
+		}
+	}
+
+	return objects
+}
+
+// applyXcconfig layers the settings of an xcconfig file, and any files it includes, onto settings
+func applyXcconfig(settings map[string]string, path string, depth int) {
+	file, err := os.Open(path)
+	if err != nil || depth > maxSettingDepth {
+		// Missing xcconfig files are normal before `pod install` has been run, and don't affect the settings we need
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if match := xcconfigIncludePattern.FindStringSubmatch(line); match != nil {
+			includePath := match[1]
+			if !filepath.IsAbs(includePath) {
+				includePath = filepath.Join(filepath.Dir(path), includePath)
+			}
+			applyXcconfig(settings, includePath, depth+1)
+			continue
+		}
+
+		if index := strings.Index(line, "//"); index >= 0 {
+			line = strings.TrimSpace(line[:index])
+		}
+
+		match := xcconfigAssignmentPattern.FindStringSubmatch(line)
+		if match == nil || match[2] != "" {
+			continue
+		}
+
+		setBuildSetting(settings, match[1], strings.TrimSpace(match[3]))
+	}
+}
+
+// setBuildSetting sets a build setting, substituting $(inherited) with its current value
+func setBuildSetting(settings map[string]string, key, value string) {
+	inherited := settings[key]
+
+	for _, reference := range []string{"$(inherited)", "${inherited}", "$inherited"} {
+		value = strings.ReplaceAll(value, reference, inherited)
+	}
+
+	settings[key] = strings.TrimSpace(value)
+}
+
+// setBuildSettingDefault sets a build setting if the project hasn't already set it
+func setBuildSettingDefault(settings map[string]string, key, value string) {
+	if _, ok := settings[key]; !ok {
+		settings[key] = value
+	}
+}
+
+// resolveBuildSetting expands the references to other build settings in the value of a build setting
+func resolveBuildSetting(settings map[string]string, key string, depth int) string {
+	value, ok := settings[key]
+	if !ok || depth > maxSettingDepth {
+		return ""
+	}
+
+	return settingReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := settingReferencePattern.FindStringSubmatch(reference)
+		name, modifiers := match[1], match[2]
+		if match[3] != "" {
+			name, modifiers = match[3], match[4]
+		} else if match[5] != "" {
+			name = match[5]
+		}
+
+		return applyBuildSettingModifiers(resolveBuildSetting(settings, name, depth+1), modifiers)
+	})
+}
+
+// applyBuildSettingModifiers applies modifiers such as :rfc1034identifier or :lower to a build setting value
+func applyBuildSettingModifiers(value, modifiers string) string {
+	for _, modifier := range strings.Split(strings.TrimPrefix(modifiers, ":"), ":") {
+		name, argument, _ := strings.Cut(modifier, "=")
+
+		switch name {
+		case "lower":
+			value = strings.ToLower(value)
+		case "upper":
+			value = strings.ToUpper(value)
+		case "identifier", "c99extidentifier":
+			value = replaceNonIdentifierChars(value, '_')
+		case "rfc1034identifier":
+			value = replaceNonIdentifierChars(value, '-')
+		case "base":
+			value = strings.TrimSuffix(filepath.Base(value), filepath.Ext(value))
+		case "file":
+			value = filepath.Base(value)
+		case "dir":
+			value = filepath.Dir(value)
+		case "suffix":
+			value = filepath.Ext(value)
+		case "standardizepath":
+			value = filepath.Clean(value)
+		case "default":
+			if value == "" {
+				value = argument
+			}
+		case "prefix":
+			if value != "" {
+				value = argument + value
+			}
+		}
+	}
+
+	return value
+}
+
+// replaceNonIdentifierChars replaces every character that isn't a letter or digit
+func replaceNonIdentifierChars(value string, replacement rune) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return replacement
+	}, value)
+}
+
+// buildSettingString returns a build setting value as a string, joining list values with spaces
+func buildSettingString(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		var parts []string
+		for _, item := range list {
+			parts = append(parts, plistString(item))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	return plistString(value)
+}
+
+// plistArray returns a plist value as an array, or nil if it isn't one
+func plistArray(value interface{}) []interface{} {
+	array, _ := value.([]interface{})
+	return array
+}
+
+// getDerivedDataDir returns the DerivedData directory that Xcode uses when building a workspace or project
+func getDerivedDataDir(containerPath string) (string, error) {
+	absolutePath, err := filepath.Abs(containerPath)
+	if err != nil {
+		return "", err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(absolutePath), filepath.Ext(absolutePath))
+	name = strings.ReplaceAll(name, " ", "_")
+
+	return filepath.Join(homeDir, "Library", "Developer", "Xcode", "DerivedData", name+"-"+getDerivedDataHash(absolutePath)), nil
+}
+
+// getDerivedDataHash returns the 28 character hash of a path that Xcode appends to DerivedData directory names
+func getDerivedDataHash(path string) string {
+	digest := md5.Sum([]byte(path))
+	hash := make([]byte, 28)
+
+	for _, half := range []struct {
+		value uint64
+		end   int
+	}{
+		{binary.BigEndian.Uint64(digest[0:8]), 13},
+		{binary.BigEndian.Uint64(digest[8:16]), 27},
+	} {
+		value := half.value
+		for index := half.end; index > half.end-14; index-- {
+			hash[index] = byte('a' + value%26)
+			value /= 26
+		}
+	}
+
+	return string(hash)
+}
@@ -0,0 +1,346 @@
+package ios
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// XcodeScheme is a scheme defined in, or autocreated for, an Xcode workspace or project
+type XcodeScheme struct {
+	Name string
+	// Path is the .xcscheme file, or empty if the scheme is autocreated for a target
+	Path string
+	// ContainerPath is the .xcworkspace or .xcodeproj that the scheme belongs to
+	ContainerPath string
+}
+
+// xcscheme is the part of an .xcscheme file needed to find the target that a scheme builds
+type xcscheme struct {
+	BuildActionEntries []struct {
+		BuildForArchiving  string             `xml:"buildForArchiving,attr"`
+		BuildableReference buildableReference `xml:"BuildableReference"`
+	} `xml:"BuildAction>BuildActionEntries>BuildActionEntry"`
+	LaunchRunnable buildableReference `xml:"LaunchAction>BuildableProductRunnable>BuildableReference"`
+	LaunchMacro    buildableReference `xml:"LaunchAction>MacroExpansion>BuildableReference"`
+}
+
+type buildableReference struct {
+	BlueprintName       string `xml:"BlueprintName,attr"`
+	ReferencedContainer string `xml:"ReferencedContainer,attr"`
+}
+
+// xcworkspace is a contents.xcworkspacedata file
+type xcworkspace struct {
+	Groups   []xcworkspaceGroup `xml:"Group"`
+	FileRefs []xcworkspaceItem  `xml:"FileRef"`
+}
+
+type xcworkspaceGroup struct {
+	Location string             `xml:"location,attr"`
+	Groups   []xcworkspaceGroup `xml:"Group"`
+	FileRefs []xcworkspaceItem  `xml:"FileRef"`
+}
+
+type xcworkspaceItem struct {
+	Location string `xml:"location,attr"`
+}
+
+// FindXcodeSchemes lists the schemes that xcodebuild would list for a workspace, project or directory containing one
+func FindXcodeSchemes(path string) ([]*XcodeScheme, error) {
+	containerPath, err := resolveXcodeContainer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemes []*XcodeScheme
+	if strings.HasSuffix(containerPath, ".xcworkspace") {
+		schemes = findSchemeFiles(containerPath)
+
+		projects, err := GetWorkspaceProjects(containerPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, projectPath := range projects {
+			schemes = append(schemes, findProjectSchemes(projectPath)...)
+		}
+	} else {
+		schemes = findProjectSchemes(containerPath)
+	}
+
+	seen := make(map[string]bool)
+	var uniqueSchemes []*XcodeScheme
+	for _, scheme := range schemes {
+		if !seen[scheme.Name] {
+			seen[scheme.Name] = true
+			uniqueSchemes = append(uniqueSchemes, scheme)
+		}
+	}
+
+	sort.SliceStable(uniqueSchemes, func(i, j int) bool { return uniqueSchemes[i].Name < uniqueSchemes[j].Name })
+
+	return uniqueSchemes, nil
+}
+
+// GetWorkspaceProjects returns the paths of the existing projects referenced by a workspace
+func GetWorkspaceProjects(workspacePath string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(workspacePath, "contents.xcworkspacedata"))
+	if err != nil {
+		return nil, err
+	}
+
+	var workspace xcworkspace
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := xml.Unmarshal(data, &workspace); err != nil {
+			return nil, errors.Errorf("unable to parse %s: %s", workspacePath, err)
+		}
+	}
+
+	root := xcworkspaceGroup{Groups: workspace.Groups, FileRefs: workspace.FileRefs}
+
+	return findWorkspaceProjects(root, filepath.Dir(workspacePath), workspacePath), nil
+}
+
+// findWorkspaceProjects returns the projects in a workspace group, resolving locations against the group's directory
+func findWorkspaceProjects(group xcworkspaceGroup, groupDir, workspacePath string) []string {
+	var projects []string
+
+	for _, fileRef := range group.FileRefs {
+		projectPath := resolveWorkspaceLocation(fileRef.Location, groupDir, workspacePath)
+		if strings.HasSuffix(projectPath, ".xcodeproj") && utils.IsDir(projectPath) {
+			projects = append(projects, projectPath)
+		}
+	}
+
+	for _, child := range group.Groups {
+		childDir := resolveWorkspaceLocation(child.Location, groupDir, workspacePath)
+		if childDir == "" {
+			childDir = groupDir
+		}
+		projects = append(projects, findWorkspaceProjects(child, childDir, workspacePath)...)
+	}
+
+	return projects
+}
+
+// resolveWorkspaceLocation returns the path of a workspace location such as group:App.xcodeproj
+func resolveWorkspaceLocation(location, groupDir, workspacePath string) string {
+	kind, path, _ := strings.Cut(location, ":")
+
+	switch kind {
+	case "group":
+		return filepath.Join(groupDir, path)
+	case "container":
+		return filepath.Join(filepath.Dir(workspacePath), path)
+	case "absolute":
+		return path
+	case "self":
+		// project.xcworkspace inside an .xcodeproj refers to the project itself
+		return filepath.Dir(workspacePath)
+	}
+
+	return ""
+}
+
+// findProjectSchemes lists the schemes of a project, autocreating one per target if it has no scheme data
+func findProjectSchemes(projectPath string) []*XcodeScheme {
+	schemes := findSchemeFiles(projectPath)
+
+	project, err := LoadXcodeProject(projectPath)
+	if err != nil {
+		return schemes
+	}
+
+	targets := project.Targets()
+
+	// Schemes autocreated by Xcode are recorded in the scheme management plist but have no .xcscheme file
+	managedSchemes := findManagedSchemeNames(projectPath)
+	for _, name := range managedSchemes {
+		if utils.ContainsString(targets, name) {
+			schemes = append(schemes, &XcodeScheme{Name: name, ContainerPath: projectPath})
+		}
+	}
+
+	if len(schemes) == 0 && len(managedSchemes) == 0 {
+		for _, target := range targets {
+			schemes = append(schemes, &XcodeScheme{Name: target, ContainerPath: projectPath})
+		}
+	}
+
+	return schemes
+}
+
+// findSchemeFiles lists the shared and user .xcscheme files of a workspace or project
+func findSchemeFiles(containerPath string) []*XcodeScheme {
+	var schemes []*XcodeScheme
+
+	patterns := []string{
+		filepath.Join(containerPath, "xcshareddata", "xcschemes", "*.xcscheme"),
+		filepath.Join(containerPath, "xcuserdata", "*.xcuserdatad", "xcschemes", "*.xcscheme"),
+	}
+
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			schemes = append(schemes, &XcodeScheme{
+				Name:          strings.TrimSuffix(filepath.Base(match), ".xcscheme"),
+				Path:          match,
+				ContainerPath: containerPath,
+			})
+		}
+	}
+
+	return schemes
+}
+
+// findManagedSchemeNames returns the scheme names recorded in the xcschememanagement.plist files of a project
+func findManagedSchemeNames(projectPath string) []string {
+	var names []string
+
+	matches, _ := filepath.Glob(filepath.Join(projectPath, "xcuserdata", "*.xcuserdatad", "xcschemes", "xcschememanagement.plist"))
+	for _, match := range matches {
+		data, err := os.ReadFile(match)
+		if err != nil {
+			continue
+		}
+
+		value, err := DecodePlist(data)
+		if err != nil {
+			continue
+		}
+
+		plist, _ := value.(map[string]interface{})
+		userState, _ := plist["SchemeUserState"].(map[string]interface{})
+		for key := range userState {
+			key = strings.TrimSuffix(key, "_^#shared#^_")
+			names = append(names, strings.TrimSuffix(key, ".xcscheme"))
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// BuildTarget returns the project and the name of the target that a scheme builds and runs
+func (s *XcodeScheme) BuildTarget() (string, string, error) {
+	if s.Path == "" {
+		return s.ContainerPath, s.Name, nil
+	}
+
+	scheme, err := s.read()
+	if err != nil {
+		return "", "", err
+	}
+
+	reference := scheme.LaunchRunnable
+	if reference.BlueprintName == "" {
+		reference = scheme.LaunchMacro
+	}
+	if reference.BlueprintName == "" {
+		for _, entry := range scheme.BuildActionEntries {
+			if entry.BuildForArchiving == "YES" {
+				reference = entry.BuildableReference
+				break
+			}
+		}
+	}
+	if reference.BlueprintName == "" && len(scheme.BuildActionEntries) > 0 {
+		reference = scheme.BuildActionEntries[0].BuildableReference
+	}
+	if reference.BlueprintName == "" {
+		return "", "", errors.Errorf("Scheme '%s' does not build any targets", s.Name)
+	}
+
+	projectPath := resolveWorkspaceLocation(reference.ReferencedContainer, filepath.Dir(s.ContainerPath), s.ContainerPath)
+	if projectPath == "" {
+		return "", "", errors.Errorf("Unable to locate the project referenced by scheme '%s'", s.Name)
+	}
+
+	return projectPath, reference.BlueprintName, nil
+}
+
+// read parses the .xcscheme file of a scheme
+func (s *XcodeScheme) read() (*xcscheme, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var scheme xcscheme
+	if err := xml.Unmarshal(data, &scheme); err != nil {
+		return nil, errors.Errorf("unable to parse %s: %s", s.Path, err)
+	}
+
+	return &scheme, nil
+}
+
+// getNativeXcodeBuildSettings resolves the build settings of a scheme by parsing the Xcode project files
+func getNativeXcodeBuildSettings(path, schemeName string) (map[string]string, error) {
+	containerPath, err := resolveXcodeContainer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	schemes, err := FindXcodeSchemes(containerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scheme := range schemes {
+		if scheme.Name != schemeName {
+			continue
+		}
+
+		projectPath, targetName, err := scheme.BuildTarget()
+		if err != nil {
+			return nil, err
+		}
+
+		project, err := LoadXcodeProject(projectPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// xcodebuild -showBuildSettings uses the project's default configuration rather than the one the scheme launches with
+		settings, err := project.BuildSettings(targetName, "", containerPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// Settings such as conditional or custom build locations are left to xcodebuild to resolve
+		buildDir := settings["CONFIGURATION_BUILD_DIR"]
+		if buildDir == "" || strings.Contains(buildDir, "$(") || strings.Contains(buildDir, "${") {
+			return nil, errors.Errorf("Unable to resolve the build directory of scheme '%s'", schemeName)
+		}
+
+		return settings, nil
+	}
+
+	return nil, errors.Errorf("Unable to locate scheme '%s' in location: '%s'", schemeName, path)
+}
+
+// resolveXcodeContainer returns the workspace or project at a path, or in the directory at a path
+func resolveXcodeContainer(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+
+	path = filepath.Clean(path)
+	if !strings.HasSuffix(path, ".xcworkspace") && !strings.HasSuffix(path, ".xcodeproj") {
+		path = FindXcodeProjOrWorkspace(path)
+	}
+
+	if path == "" || !utils.IsDir(path) {
+		return "", errors.New("Unable to locate xcodeproj or xcworkspace in the given path")
+	}
+
+	return path, nil
+}
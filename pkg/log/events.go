@@ -0,0 +1,215 @@
+package log
+
+import (
+	"sync"
+)
+
+// File statuses
+const (
+	StatusUploaded    = "uploaded"
+	StatusDuplicate   = "duplicate"
+	StatusDryRun      = "dry-run"
+	StatusFailed      = "failed"
+	StatusSkipped     = "skipped"
+	StatusNotUploaded = "not-uploaded"
+)
+
+// FileEvent - Describes what happened to a single file during the run
+type FileEvent struct {
+	File       string            `json:"file"`
+	UploadType string            `json:"uploadType,omitempty"`
+	BuildId    string            `json:"buildId,omitempty"`
+	Endpoint   string            `json:"endpoint,omitempty"`
+	Status     string            `json:"status"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	DurationMs int64             `json:"durationMs"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Summary counts of the files processed during the run
+type summary struct {
+	Files       int `json:"files"`
+	Uploaded    int `json:"uploaded"`
+	Duplicate   int `json:"duplicate"`
+	DryRun      int `json:"dryRun"`
+	Failed      int `json:"failed"`
+	Skipped     int `json:"skipped"`
+	NotUploaded int `json:"notUploaded"`
+}
+
+var events []FileEvent
+var fileOrder []string
+var fileMetadata = make(map[string]map[string]string)
+var fileAliases = make(map[string]string)
+var finished bool
+var eventsMutex sync.Mutex
+
+// DiscoverFile - Registers a file found while looking for files to upload, along with any identifying
+// metadata (e.g. UUID or architecture) which is included in the events recorded for it
+func DiscoverFile(file string, metadata map[string]string) {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+
+	file = resolveAlias(file)
+	addFile(file)
+
+	if fileMetadata[file] == nil {
+		fileMetadata[file] = make(map[string]string)
+	}
+
+	for key, value := range metadata {
+		if value != "" {
+			fileMetadata[file][key] = value
+		}
+	}
+}
+
+// AliasFile - Records that a generated file (e.g. a compressed copy in a temporary directory) is uploaded
+// on behalf of a discovered file, so that its events are reported against the original
+func AliasFile(generatedFile string, file string) {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+
+	fileAliases[generatedFile] = resolveAlias(file)
+}
+
+// RecordFile - Records what happened to a file and, in JSON mode, prints it as an event
+func RecordFile(event FileEvent) {
+	eventsMutex.Lock()
+
+	event.File = resolveAlias(event.File)
+	addFile(event.File)
+
+	metadata := make(map[string]string)
+
+	for key, value := range fileMetadata[event.File] {
+		metadata[key] = value
+	}
+
+	for key, value := range event.Metadata {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+
+	if len(metadata) > 0 {
+		event.Metadata = metadata
+	}
+
+	if event.BuildId == "" {
+		for _, key := range []string{"buildUUID", "buildId", "uuid"} {
+			if metadata[key] != "" {
+				event.BuildId = metadata[key]
+				break
+			}
+		}
+	}
+
+	events = append(events, event)
+
+	eventsMutex.Unlock()
+
+	if IsJson() {
+		PrintJson(struct {
+			Type string `json:"type"`
+			FileEvent
+		}{"upload", event})
+	}
+}
+
+// Events - Returns every file event recorded so far
+func Events() []FileEvent {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+
+	return append([]FileEvent(nil), events...)
+}
+
+// Finish - Prints the summary in JSON mode. Only the first call has any effect.
+func Finish() {
+	eventsMutex.Lock()
+
+	if finished {
+		eventsMutex.Unlock()
+		return
+	}
+
+	finished = true
+	outcomes := fileOutcomes()
+
+	eventsMutex.Unlock()
+
+	if IsJson() {
+		PrintJson(struct {
+			Type string `json:"type"`
+			summary
+		}{"summary", summarise(outcomes)})
+	}
+}
+
+// fileOutcomes - Returns the final state of every file seen during the run, in the order they were first seen.
+// A file can have several events (e.g. when retried against a legacy endpoint), the last one is its outcome.
+func fileOutcomes() []FileEvent {
+	latest := make(map[string]FileEvent)
+
+	for _, event := range events {
+		latest[event.File] = event
+	}
+
+	var outcomes []FileEvent
+
+	for _, file := range fileOrder {
+		event, ok := latest[file]
+		if !ok {
+			event = FileEvent{File: file, Status: StatusNotUploaded, Metadata: fileMetadata[file]}
+		}
+
+		outcomes = append(outcomes, event)
+	}
+
+	return outcomes
+}
+
+// summarise - Counts the files in each status
+func summarise(outcomes []FileEvent) summary {
+	result := summary{Files: len(outcomes)}
+
+	for _, event := range outcomes {
+		switch event.Status {
+		case StatusUploaded:
+			result.Uploaded++
+		case StatusDuplicate:
+			result.Duplicate++
+		case StatusDryRun:
+			result.DryRun++
+		case StatusFailed:
+			result.Failed++
+		case StatusSkipped:
+			result.Skipped++
+		case StatusNotUploaded:
+			result.NotUploaded++
+		}
+	}
+
+	return result
+}
+
+// addFile - Adds a file to the ordered list of files seen during the run
+func addFile(file string) {
+	if _, ok := fileMetadata[file]; ok {
+		return
+	}
+
+	fileMetadata[file] = nil
+	fileOrder = append(fileOrder, file)
+}
+
+// resolveAlias - Returns the discovered file that a generated file stands in for
+func resolveAlias(file string) string {
+	if original, ok := fileAliases[file]; ok {
+		return original
+	}
+
+	return file
+}
@@ -1,9 +1,12 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/mattn/go-isatty"
 	"os"
+	"strings"
+	"sync"
 )
 
 const Reset = "\033[0m"
@@ -12,7 +15,44 @@ const Green = "\033[32m"
 const Yellow = "\033[33m"
 const White = "\033[37m"
 
+// Output formats
+const (
+	TextFormat = "text"
+	JsonFormat = "json"
+)
+
+// logEvent - A log message written in JSON format
+type logEvent struct {
+	Type    string `json:"type"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+var format = TextFormat
+var outputMutex sync.Mutex
+
+// SetFormat - Sets the format used for all output, either TextFormat or JsonFormat. Log configuration is the one
+// option kept as package state rather than passed as a parameter, as every package writes through this logger.
+func SetFormat(outputFormat string) {
+	format = outputFormat
+}
+
+// IsJson - Returns true when output is being written as JSON
+func IsJson() bool {
+	return format == JsonFormat
+}
+
 func LogMessage(message string, status string, color string) {
+	message = Redact(message)
+
+	if IsJson() {
+		PrintJson(logEvent{Type: "log", Level: strings.ToLower(status), Message: message})
+		return
+	}
+
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
 	if isatty.IsTerminal(os.Stdout.Fd()) {
 		fmt.Println("[" + color + status + Reset + "] " + message)
 	} else {
@@ -20,9 +60,23 @@ func LogMessage(message string, status string, color string) {
 	}
 }
 
+// PrintJson - Writes a value to stdout as a single line of JSON, masking any secrets
+func PrintJson(value interface{}) {
+	line, err := json.Marshal(value)
+	if err != nil {
+		line, _ = json.Marshal(logEvent{Type: "log", Level: "error", Message: err.Error()})
+	}
+
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
+	fmt.Println(Redact(string(line)))
+}
+
 // Error - Displays error message and exits with a status code
 func Error(message string, statusCode int) {
 	LogMessage(message, "ERROR", Red)
+	Finish()
 	os.Exit(statusCode)
 }
 
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Matches the value of an API key field in JSON, YAML or form style text, e.g. "apiKey": "<key>" or api_key=<key>
+var apiKeyFieldPattern = regexp.MustCompile(`(?i)("?api[-_]?key"?\s*[:=]\s*"?)([^"'\s,&}]+)`)
+
+var secrets []string
+var showSecrets bool
+var secretsMutex sync.RWMutex
+
+// AddSecret - Registers a value (e.g. an API key) to be masked wherever it appears in the output
+func AddSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	for _, existing := range secrets {
+		if existing == secret {
+			return
+		}
+	}
+
+	secrets = append(secrets, secret)
+}
+
+// SetShowSecrets - Turns off redaction so that secrets are written in full, for local debugging. Like the output
+// format, this is log configuration, which is kept as package state rather than passed as a parameter.
+func SetShowSecrets(show bool) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	showSecrets = show
+}
+
+// Redact - Masks every registered secret, and the value of any API key field, in the given text
+func Redact(text string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+
+	if showSecrets {
+		return text
+	}
+
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, mask(secret))
+	}
+
+	return apiKeyFieldPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := apiKeyFieldPattern.FindStringSubmatch(match)
+		if strings.HasPrefix(parts[2], "****") {
+			return match
+		}
+
+		return parts[1] + mask(parts[2])
+	})
+}
+
+// mask - Hides all but the last 4 characters of a secret, or all of it if it is too short to reveal any
+func mask(secret string) string {
+	if len(secret) < 16 {
+		return "****"
+	}
+
+	return "****" + secret[len(secret)-4:]
+}
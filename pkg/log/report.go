@@ -0,0 +1,169 @@
+package log
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// report - The JSON report of every file processed during the run
+type report struct {
+	GeneratedAt string      `json:"generatedAt"`
+	Summary     summary     `json:"summary"`
+	Files       []FileEvent `json:"files"`
+}
+
+// JUnit report structure, with each file as a test case
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       string           `xml:"time,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitMessage    `xml:"failure,omitempty"`
+	Skipped    *junitMessage    `xml:"skipped,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteReport - Writes the outcome of every file processed during the run to the given path. Paths ending in
+// .xml are written as a JUnit report, anything else as JSON.
+func WriteReport(path string) error {
+	var data []byte
+	var err error
+
+	eventsMutex.Lock()
+	outcomes := fileOutcomes()
+	eventsMutex.Unlock()
+
+	if outcomes == nil {
+		outcomes = []FileEvent{}
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		data, err = xml.MarshalIndent(buildJunitReport(outcomes), "", "  ")
+		if err == nil {
+			data = append([]byte(xml.Header), data...)
+		}
+	} else {
+		data, err = json.MarshalIndent(report{
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+			Summary:     summarise(outcomes),
+			Files:       outcomes,
+		}, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(Redact(string(data))), 0644)
+}
+
+// buildJunitReport - Converts the file outcomes into a JUnit test suite. Failed uploads are failures,
+// while files that were not uploaded for any other reason (duplicate, dry run, skipped) are skipped.
+func buildJunitReport(outcomes []FileEvent) junitTestSuites {
+	suite := junitTestSuite{
+		Name:      "bugsnag-cli",
+		Tests:     len(outcomes),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var totalMs int64
+
+	for _, event := range outcomes {
+		totalMs += event.DurationMs
+
+		testCase := junitTestCase{
+			Name:      event.File,
+			ClassName: "bugsnag-cli." + event.UploadType,
+			Time:      formatSeconds(event.DurationMs),
+		}
+
+		if event.UploadType == "" {
+			testCase.ClassName = "bugsnag-cli"
+		}
+
+		if event.BuildId != "" || len(event.Metadata) > 0 {
+			testCase.Properties = &junitProperties{}
+
+			if event.BuildId != "" {
+				testCase.Properties.Properties = append(testCase.Properties.Properties, junitProperty{Name: "buildId", Value: event.BuildId})
+			}
+
+			for _, key := range sortedKeys(event.Metadata) {
+				testCase.Properties.Properties = append(testCase.Properties.Properties, junitProperty{Name: key, Value: event.Metadata[key]})
+			}
+		}
+
+		switch event.Status {
+		case StatusUploaded:
+		case StatusFailed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: "Upload failed", Text: event.Error}
+		default:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: event.Status}
+		}
+
+		if len(event.Warnings) > 0 {
+			testCase.SystemOut = strings.Join(event.Warnings, "\n")
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Time = formatSeconds(totalMs)
+
+	return junitTestSuites{Suites: []junitTestSuite{suite}}
+}
+
+// formatSeconds - Formats a duration in milliseconds as seconds for JUnit
+func formatSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// sortedKeys - Returns the keys of a map in alphabetical order
+func sortedKeys(values map[string]string) []string {
+	var keys []string
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,259 @@
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames - The names of the project configuration file, searched for upward from the working directory
+var ConfigFileNames = []string{".bugsnag-cli.yml", ".bugsnag-cli.yaml"}
+
+// ConfigResolver - Resolves flag values from a project configuration file.
+//
+// Top-level keys apply to every command, while keys nested under a command (e.g. `upload` or
+// `upload: android-ndk:`) only apply to that command and override the less specific ones.
+// Keys are flag names, with `${VAR}` references expanded from the environment.
+//
+// Kong only asks resolvers about flags that weren't given on the command line, and flags whose
+// environment variable is set are skipped, so the precedence is flags > env > config file.
+// Anything still unset is left to the auto-detection done by each command.
+type ConfigResolver struct {
+	path   string
+	values map[string]interface{}
+	loaded bool
+}
+
+// NewConfigResolver - Creates a resolver which loads the configuration file the first time it is used
+func NewConfigResolver() *ConfigResolver {
+	return &ConfigResolver{}
+}
+
+// Path - Returns the path of the configuration file that was loaded, if any
+func (r *ConfigResolver) Path() string {
+	return r.path
+}
+
+// Validate - Implements kong.Resolver, there is nothing to validate up front
+func (r *ConfigResolver) Validate(app *kong.Application) error {
+	return nil
+}
+
+// Resolve - Implements kong.Resolver, returning the configured value for a flag or nil if it isn't configured
+func (r *ConfigResolver) Resolve(context *kong.Context, parent *kong.Path, flag *kong.Flag) (interface{}, error) {
+	if flag.Name == "config" || flag.Name == "help" || flag.Name == "version" {
+		return nil, nil
+	}
+
+	if flag.Tag.Env != "" && os.Getenv(flag.Tag.Env) != "" {
+		return nil, nil
+	}
+
+	if !r.loaded {
+		r.loaded = true
+
+		err := r.load(configFlagValue(context))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.values == nil {
+		return nil, nil
+	}
+
+	commands := selectedCommands(context)
+
+	// Look in the section for the selected command first, then each of its parents
+	for i := len(commands); i >= 0; i-- {
+		section := findSection(r.values, commands[:i])
+		if section == nil {
+			continue
+		}
+
+		value := findKey(section, flag.Name)
+		if value == nil {
+			continue
+		}
+
+		_, isMap := value.(map[string]interface{})
+		if isMap && flag.Target.Kind() != reflect.Map {
+			// A nested command section rather than a value
+			continue
+		}
+
+		return r.convertValue(value, flag), nil
+	}
+
+	return nil, nil
+}
+
+// load - Reads the configuration file given with --config, or the nearest one found from the working directory
+func (r *ConfigResolver) load(path string) error {
+	if path == "" {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			return nil
+		}
+
+		path = FindConfigFile(workingDir)
+		if path == "" {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read configuration file %s: %w", path, err)
+	}
+
+	values := make(map[string]interface{})
+
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("unable to parse configuration file %s: %w", path, err)
+	}
+
+	r.path = path
+	r.values = expandEnv(values).(map[string]interface{})
+
+	return nil
+}
+
+// convertValue - Converts a value from the configuration file into a form that kong can map onto the flag.
+// Relative paths are resolved against the directory containing the configuration file.
+func (r *ConfigResolver) convertValue(value interface{}, flag *kong.Flag) interface{} {
+	switch v := value.(type) {
+	case string:
+		if flag.Tag.Type == "path" && v != "" && !filepath.IsAbs(v) {
+			return filepath.Join(filepath.Dir(r.path), v)
+		}
+		return v
+
+	case []interface{}:
+		var values []interface{}
+		for _, element := range v {
+			values = append(values, r.convertValue(element, flag))
+		}
+		return values
+
+	case map[string]interface{}:
+		values := make(map[string]interface{})
+		for key, element := range v {
+			values[key] = fmt.Sprint(element)
+		}
+		return values
+
+	default:
+		if flag.Target.Kind() == reflect.String {
+			return fmt.Sprint(v)
+		}
+		return v
+	}
+}
+
+// FindConfigFile - Searches the given directory and its parents for a project configuration file
+//
+// Returns the path of the first configuration file found, or an empty string if there isn't one
+func FindConfigFile(dir string) string {
+	for {
+		for _, name := range ConfigFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// configFlagValue - Returns the path given with --config on the command line, if any
+func configFlagValue(context *kong.Context) string {
+	for _, flag := range context.Flags() {
+		if flag.Name == "config" {
+			if value := reflect.ValueOf(context.FlagValue(flag)); value.Kind() == reflect.String {
+				return value.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// selectedCommands - Returns the names of the commands leading to the selected one, e.g. [upload android-ndk]
+func selectedCommands(context *kong.Context) []string {
+	var commands []string
+
+	for node := context.Selected(); node != nil; node = node.Parent {
+		if node.Type == kong.CommandNode {
+			commands = append([]string{node.Name}, commands...)
+		}
+	}
+
+	return commands
+}
+
+// findSection - Returns the section of the configuration for the given commands, or nil if there isn't one
+func findSection(values map[string]interface{}, commands []string) map[string]interface{} {
+	section := values
+
+	for _, command := range commands {
+		next, ok := findKey(section, command).(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		section = next
+	}
+
+	return section
+}
+
+// findKey - Returns the value for a flag or command name, allowing snake_case as well as kebab-case keys
+func findKey(section map[string]interface{}, name string) interface{} {
+	if value, ok := section[name]; ok {
+		return value
+	}
+
+	for key, value := range section {
+		if strings.ReplaceAll(key, "_", "-") == name {
+			return value
+		}
+	}
+
+	return nil
+}
+
+// expandEnv - Expands environment variable references in every string in the configuration
+func expandEnv(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return os.ExpandEnv(v)
+
+	case []interface{}:
+		for i, element := range v {
+			v[i] = expandEnv(element)
+		}
+		return v
+
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = expandEnv(element)
+		}
+		return v
+
+	default:
+		return v
+	}
+}
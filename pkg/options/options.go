@@ -7,13 +7,16 @@ import (
 
 // Global CLI options
 type Globals struct {
-	UploadAPIRootUrl  string            `help:"Bugsnag On-Premise upload server URL. Can contain port number" default:"https://upload.bugsnag.com"`
-	BuildApiRootUrl   string            `help:"Bugsnag On-Premise build server URL. Can contain port number" default:"https://build.bugsnag.com"`
-	Port              int               `help:"Port number for the upload server" default:"443"`
-	ApiKey            string            `help:"(required) Bugsnag integration API key for this application"`
-	FailOnUploadError bool              `help:"Stops the upload when a mapping file fails to upload to Bugsnag successfully" default:"false"`
+	UploadAPIRootUrl  string            `help:"Bugsnag On-Premise upload server URL. Can contain port number" default:"https://upload.bugsnag.com" env:"BUGSNAG_UPLOAD_API_ROOT_URL"`
+	BuildApiRootUrl   string            `help:"Bugsnag On-Premise build server URL. Can contain port number" default:"https://build.bugsnag.com" env:"BUGSNAG_BUILD_API_ROOT_URL"`
+	Port              int               `help:"Port number for the upload server" default:"443" env:"BUGSNAG_PORT"`
+	ApiKey            string            `help:"(required) Bugsnag integration API key for this application" env:"BUGSNAG_API_KEY"`
+	FailOnUploadError bool              `help:"Stops the upload when a mapping file fails to upload to Bugsnag successfully" default:"false" env:"BUGSNAG_FAIL_ON_UPLOAD_ERROR"`
 	Version           utils.VersionFlag `name:"version" help:"Print version information and quit"`
-	DryRun            bool              `help:"Validate but do not process"`
+	DryRun            bool              `help:"Validate but do not process" env:"BUGSNAG_DRY_RUN"`
+	Output            string            `help:"Output format, use 'json' to write machine-readable events and a summary for each file processed" enum:"text,json" default:"text" env:"BUGSNAG_OUTPUT"`
+	ShowSecrets       bool              `help:"Show API keys and other secrets in the output instead of masking them, for local debugging" env:"BUGSNAG_SHOW_SECRETS"`
+	Config            utils.Path        `help:"Path to a project configuration file. Defaults to the nearest .bugsnag-cli.yml in the working directory or its parents" type:"path" env:"BUGSNAG_CONFIG"`
 }
 
 // Unique CLI options
@@ -22,12 +25,16 @@ type CLI struct {
 
 	Upload struct {
 		// shared options
-		Overwrite bool `help:"Whether to overwrite any existing symbol file with a matching ID"`
-		Timeout   int  `help:"Number of seconds to wait before failing an upload request" default:"300"`
-		Retries   int  `help:"Number of retry attempts before failing an upload request" default:"0"`
+		Overwrite     bool   `help:"Whether to overwrite any existing symbol file with a matching ID" env:"BUGSNAG_OVERWRITE"`
+		Timeout       int    `help:"Number of seconds to wait before failing an upload request" default:"300" env:"BUGSNAG_TIMEOUT"`
+		Retries       int    `help:"Number of retry attempts before failing an upload request" default:"0" env:"BUGSNAG_RETRIES"`
+		RetryMaxDelay int    `help:"Maximum number of seconds to wait between retry attempts" default:"30" env:"BUGSNAG_RETRY_MAX_DELAY"`
+		Concurrency   int    `help:"Number of files to upload in parallel" default:"1" env:"BUGSNAG_CONCURRENCY"`
+		Report        string `help:"Path to write a report of every file processed. Written as JUnit XML if the path ends in .xml, otherwise as JSON" type:"path" env:"BUGSNAG_REPORT"`
 
 		// required options
 		AndroidAab         upload.AndroidAabMapping      `cmd:"" help:"Process and upload application bundle files for Android"`
+		AndroidApk         upload.AndroidApkMapping      `cmd:"" help:"Process and upload NDK symbol files and mapping files for Android APKs"`
 		All                upload.DiscoverAndUploadAny   `cmd:"" help:"Upload any symbol/mapping files"`
 		AndroidNdk         upload.AndroidNdkMapping      `cmd:"" help:"Process and upload NDK symbol files for Android"`
 		AndroidProguard    upload.AndroidProguardMapping `cmd:"" help:"Process and upload Proguard/R8 mapping files for Android"`
@@ -39,12 +46,17 @@ type CLI struct {
 	} `cmd:"" help:"Upload symbol/mapping files"`
 	CreateBuild          CreateBuild          `cmd:"" help:"Provide extra information whenever you build, release, or deploy your application"`
 	CreateAndroidBuildId CreateAndroidBuildId `cmd:"" help:"Generate a reproducible Build ID from .dex files"`
+	Inspect              Inspect              `cmd:"" help:"Print the identifying metadata of symbol and mapping files, such as UUIDs and build IDs"`
 }
 
 type CreateAndroidBuildId struct {
 	Path utils.Paths `arg:"" name:"path" help:"Path to the project directory" type:"path"`
 }
 
+type Inspect struct {
+	Path utils.Paths `arg:"" name:"path" help:"Path to the dSYMs, shared objects, Dart symbol files, mapping files, source maps or AABs to inspect" type:"path"`
+}
+
 type AndroidBuildOptions struct {
 	VersionCode string     `help:"The version code for the application (Android only)." xor:"app-version-code,version-code"`
 	AppManifest utils.Path `help:"The path to the Android manifest file"`
@@ -67,6 +79,7 @@ type CreateBuild struct {
 	AutoAssignRelease bool              `help:"Whether to automatically associate this build with any new error events and sessions that are received for the releaseStage"`
 	Timeout           int               `help:"Number of seconds to wait before failing an upload request" default:"300"`
 	Retries           int               `help:"Number of retry attempts before failing an upload request" default:"0"`
+	RetryMaxDelay     int               `help:"Maximum number of seconds to wait between retry attempts" default:"30"`
 	AndroidBuildOptions
 	IosBuildOptions
 }
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+// multipartBody describes a multipart/form-data request body as a sequence of in-memory
+// segments (part headers, form fields and boundaries) and files on disk, so that the body
+// can be streamed without holding any of the files in memory.
+type multipartBody struct {
+	segments      []bodySegment
+	contentType   string
+	contentLength int64
+}
+
+// bodySegment is either a chunk of in-memory data or the path to a file to be streamed from disk.
+type bodySegment struct {
+	data     []byte
+	filePath string
+}
+
+// newMultipartBody lays out a multipart/form-data body for the given fields and files.
+//
+// Parameters:
+//   - fieldData: A map containing additional form fields for the request.
+//   - fileFieldData: A map containing file field names and their corresponding file paths.
+//
+// Returns:
+//   - *multipartBody: The body layout, including its content type and exact length.
+//   - error: An error if any of the files cannot be read.
+func newMultipartBody(fieldData map[string]string, fileFieldData map[string]string) (*multipartBody, error) {
+	body := &multipartBody{}
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+
+	// flush moves whatever the multipart writer has produced so far into its own segment
+	flush := func() {
+		if buffer.Len() > 0 {
+			body.segments = append(body.segments, bodySegment{data: bytes.Clone(buffer.Bytes())})
+			body.contentLength += int64(buffer.Len())
+			buffer.Reset()
+		}
+	}
+
+	for key, value := range fileFieldData {
+		fileInfo, err := os.Stat(value)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = writer.CreateFormFile(key, filepath.Base(value))
+		if err != nil {
+			return nil, err
+		}
+
+		flush()
+
+		body.segments = append(body.segments, bodySegment{filePath: value})
+		body.contentLength += fileInfo.Size()
+	}
+
+	for key, value := range fieldData {
+		err := writer.WriteField(key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	flush()
+
+	body.contentType = writer.FormDataContentType()
+
+	return body, nil
+}
+
+// Reader returns a new reader over the whole body. Files are opened only when they are
+// reached, and each call starts again from the beginning so that the body can be re-sent.
+func (b *multipartBody) Reader() io.ReadCloser {
+	return &multipartBodyReader{segments: b.segments}
+}
+
+// multipartBodyReader streams the segments of a multipartBody in order.
+type multipartBodyReader struct {
+	segments []bodySegment
+	current  io.Reader
+	file     *os.File
+}
+
+func (r *multipartBodyReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.segments) == 0 {
+				return 0, io.EOF
+			}
+
+			segment := r.segments[0]
+			r.segments = r.segments[1:]
+
+			if segment.filePath != "" {
+				file, err := os.Open(segment.filePath)
+				if err != nil {
+					return 0, err
+				}
+
+				r.file = file
+				r.current = file
+			} else {
+				r.current = bytes.NewReader(segment.data)
+			}
+		}
+
+		n, err := r.current.Read(p)
+
+		if err == io.EOF {
+			r.closeFile()
+			r.current = nil
+
+			if n > 0 {
+				return n, nil
+			}
+
+			continue
+		}
+
+		return n, err
+	}
+}
+
+// Close releases any file that is still open, e.g. when a request is abandoned part way through.
+func (r *multipartBodyReader) Close() error {
+	r.closeFile()
+	r.segments = nil
+	r.current = nil
+
+	return nil
+}
+
+func (r *multipartBodyReader) closeFile() {
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file = nil
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ProcessConcurrently runs the given upload tasks using a bounded pool of workers.
+// Every task is run, even when an earlier one fails, so that a single failed file
+// does not hide problems with the others.
+//
+// Parameters:
+//   - concurrency: The maximum number of tasks to run at the same time. Values below 1 are treated as 1.
+//   - tasks: The upload tasks to run.
+//
+// Returns:
+//   - error: The errors returned by the failed tasks, in the order the tasks were given. Nil if every task succeeded.
+func ProcessConcurrently(concurrency int, tasks []func() error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if concurrency > len(tasks) {
+		concurrency = len(tasks)
+	}
+
+	results := make([]error, len(tasks))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = tasks[index]()
+			}
+		}()
+	}
+
+	for index := range tasks {
+		jobs <- index
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return joinErrors(results)
+}
+
+// joinErrors combines the non-nil errors from an ordered list of results into a single error.
+//
+// Parameters:
+//   - results: The result of each task, in task order.
+//
+// Returns:
+//   - error: Nil if there are no errors, the error itself if there is exactly one,
+//     otherwise an error listing every failure.
+func joinErrors(results []error) error {
+	var failures []error
+
+	for _, err := range results {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	switch len(failures) {
+	case 0:
+		return nil
+	case 1:
+		return failures[0]
+	default:
+		return fmt.Errorf("%d of %d uploads failed:\n%w", len(failures), len(results), errors.Join(failures...))
+	}
+}
@@ -2,22 +2,23 @@ package server
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
-
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 )
 
 // buildFileRequest constructs an HTTP request for file upload with specified field data.
+// The files are streamed from disk when the request is sent rather than being read into memory,
+// and the request can produce a fresh copy of its body for retries.
 //
 // Parameters:
 //   - url: The target URL for the file upload request.
@@ -28,88 +29,131 @@ import (
 //   - *http.Request: The constructed HTTP request.
 //   - error: An error if any step of the request construction fails.
 func buildFileRequest(url string, fieldData map[string]string, fileFieldData map[string]string) (*http.Request, error) {
-	body := &bytes.Buffer{}
-
-	writer := multipart.NewWriter(body)
-
-	for key, value := range fileFieldData {
-		file, err := os.Open(value)
-		if err != nil {
-			return nil, err
-		}
-
-		part, err := writer.CreateFormFile(key, filepath.Base(file.Name()))
-		if err != nil {
-			return nil, err
-		}
-
-		_, err = io.Copy(part, file)
-		if err != nil {
-			return nil, err
-		}
-
-		file.Close()
-	}
-
-	for key, value := range fieldData {
-		err := writer.WriteField(key, value)
-		if err != nil {
-			return nil, err
-		}
+	body, err := newMultipartBody(fieldData, fileFieldData)
+	if err != nil {
+		return nil, err
 	}
 
-	writer.Close()
-
-	request, err := http.NewRequest("POST", url, body)
+	request, err := http.NewRequest("POST", url, body.Reader())
 	if err != nil {
 		return nil, err
 	}
 
-	request.Header.Add("Content-Type", writer.FormDataContentType())
+	request.ContentLength = body.contentLength
+	request.GetBody = func() (io.ReadCloser, error) {
+		return body.Reader(), nil
+	}
+
+	request.Header.Add("Content-Type", body.contentType)
 
 	return request, nil
 }
 
 // ProcessFileRequest processes a file upload request by building an HTTP request,
 // uploading the specified file to the endpoint, and logging information based on the dryRun flag.
+// The outcome is recorded as a file event so that it can be reported at the end of the run.
 //
 // Parameters:
 //   - endpoint: The target URL for the file upload.
 //   - uploadOptions: A map containing options for building the file request.
 //   - fileFieldData: A map containing data associated with the file field.
 //   - timeout: The maximum time allowed for the HTTP request.
+//   - retries: The number of times to retry the request in case of failure.
+//   - retryMaxDelay: The longest time to wait between two attempts.
 //   - fileName: The name of the file to be uploaded.
 //   - dryRun: If true, the function performs a dry run without actually sending the file.
 //
 // Returns:
 //   - error: An error if any step of the file processing fails. Nil if the process is successful.
-func ProcessFileRequest(endpoint string, uploadOptions map[string]string, fileFieldData map[string]string, timeout int, retries int, fileName string, dryRun bool) error {
+func ProcessFileRequest(endpoint string, uploadOptions map[string]string, fileFieldData map[string]string, timeout int, retries int, retryMaxDelay time.Duration, fileName string, dryRun bool) error {
+	event := log.FileEvent{
+		File:       eventFile(fileName, fileFieldData),
+		UploadType: uploadType(endpoint),
+		Endpoint:   endpoint,
+		Metadata:   eventMetadata(uploadOptions),
+	}
+
 	req, err := buildFileRequest(endpoint, uploadOptions, fileFieldData)
 	if err != nil {
+		event.Status = log.StatusFailed
+		event.Error = err.Error()
+		log.RecordFile(event)
+
 		return fmt.Errorf("error building file request: %w", err)
 	}
 
 	if !dryRun {
 		log.Info("Uploading " + filepath.Base(fileName) + " to " + endpoint)
 
-		err = processRequest(req, timeout, retries)
+		start := time.Now()
+		event.Warnings, err = processRequest(req, timeout, retries, retryMaxDelay)
+		event.DurationMs = time.Since(start).Milliseconds()
 
 		if err != nil {
-			if strings.Contains(err.Error(), "409") {
+			var uploadErr *UploadError
+			if errors.As(err, &uploadErr) {
+				uploadErr.File = fileName
+			}
+
+			if IsDuplicate(err) {
 				log.Warn("Duplicate file detected, skipping upload of " + filepath.Base(fileName))
+				event.Status = log.StatusDuplicate
 			} else {
+				event.Status = log.StatusFailed
+				event.Error = err.Error()
+				log.RecordFile(event)
+
 				return err
 			}
 		} else {
 			log.Success("Uploaded " + filepath.Base(fileName))
+			event.Status = log.StatusUploaded
 		}
 	} else {
 		log.Info("(dryrun) Skipping upload of " + filepath.Base(fileName) + " to " + endpoint)
+		event.Status = log.StatusDryRun
 	}
 
+	log.RecordFile(event)
+
 	return nil
 }
 
+// eventFile works out which file an upload event is about. Requests with a single file report
+// its path, while requests with several files (e.g. a source map and bundle) report fileName.
+func eventFile(fileName string, fileFieldData map[string]string) string {
+	if len(fileFieldData) == 1 {
+		for _, file := range fileFieldData {
+			return file
+		}
+	}
+
+	return fileName
+}
+
+// uploadType returns the kind of upload from the last segment of the endpoint, e.g. "ndk-symbol"
+func uploadType(endpoint string) string {
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+
+	return strings.Trim(path.Base(endpointUrl.Path), "/.")
+}
+
+// eventMetadata returns the upload options that identify the uploaded file, leaving out credentials
+func eventMetadata(uploadOptions map[string]string) map[string]string {
+	metadata := make(map[string]string)
+
+	for key, value := range uploadOptions {
+		if key != "apiKey" && key != "overwrite" {
+			metadata[key] = value
+		}
+	}
+
+	return metadata
+}
+
 // ProcessBuildRequest processes a build request by creating an HTTP request with the provided payload,
 // sending the request to the specified endpoint, and logging information based on the dryRun flag.
 //
@@ -117,18 +161,20 @@ func ProcessFileRequest(endpoint string, uploadOptions map[string]string, fileFi
 //   - endpoint: The target URL for the HTTP POST request.
 //   - payload: The payload to be sent in the request body.
 //   - timeout: The maximum time allowed for the HTTP request.
+//   - retries: The number of times to retry the request in case of failure.
+//   - retryMaxDelay: The longest time to wait between two attempts.
 //   - dryRun: If true, the function performs a dry run without actually sending the request.
 //
 // Returns:
 //   - error: An error if any step of the build processing fails. Nil if the process is successful.
-func ProcessBuildRequest(endpoint string, payload []byte, timeout int, retries int, dryRun bool) error {
+func ProcessBuildRequest(endpoint string, payload []byte, timeout int, retries int, retryMaxDelay time.Duration, dryRun bool) error {
 	req, _ := http.NewRequest("POST", endpoint, bytes.NewBuffer(payload))
 	req.Header.Add("Content-Type", "application/json")
 
 	if !dryRun {
 		log.Info("Sending build information to " + endpoint)
 
-		err := processRequest(req, timeout, retries)
+		_, err := processRequest(req, timeout, retries, retryMaxDelay)
 		if err != nil {
 			return err
 		}
@@ -140,41 +186,50 @@ func ProcessBuildRequest(endpoint string, payload []byte, timeout int, retries i
 }
 
 // processRequest sends an HTTP request using sendRequest function with retry logic.
-// It attempts to send the request multiple times, specified by retryCount parameter,
-// and waits for a short duration between each attempt.
-// If all attempts fail, it returns an error indicating the failure after the specified number of attempts.
+// Network errors and retryable HTTP statuses (such as 429 and 503) are retried up to retryCount times,
+// waiting between attempts using an exponential backoff or the server's Retry-After header.
+// Any other failure is returned straight away as the request will never succeed.
 // Parameters:
 //   - request: The HTTP request to be sent.
 //   - timeout: Timeout duration for the HTTP request in seconds.
 //   - retryCount: Number of times to retry the request in case of failure.
+//   - retryMaxDelay: The longest time to wait between two attempts.
 //
 // Returns:
+//   - []string: Any warnings returned by the server.
 //   - error: An error indicating the reason for failure or nil if the request is successful.
-func processRequest(request *http.Request, timeout int, retryCount int) error {
+func processRequest(request *http.Request, timeout int, retryCount int, retryMaxDelay time.Duration) ([]string, error) {
+	var warnings []string
 	var err error
 	i := 0
 	for {
-		err = sendRequest(request, timeout)
+		// Rewind the body so that a retry doesn't send an already drained request
+		if i > 0 && request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("error rewinding request body: %w", err)
+			}
+		}
+
+		warnings, err = sendRequest(request, timeout)
 		if err == nil {
-			return nil
+			return warnings, nil
 		}
 
 		i++
 
-		if i > retryCount {
+		if i > retryCount || !isRetryable(err) {
 			break
 		}
 
-		log.Warn("Request Failed, Retrying...")
+		delay := retryDelay(i, err, retryMaxDelay)
 
-		time.Sleep(time.Second)
-	}
+		log.Warn(fmt.Sprintf("Request failed, retrying in %s...", delay.Round(time.Millisecond)))
 
-	if err != nil {
-		return errors.Errorf("failed after %d attempts. %s", i, err.Error())
+		time.Sleep(delay)
 	}
 
-	return nil
+	return warnings, fmt.Errorf("failed after %d attempts. %w", i, err)
 }
 
 // sendRequest sends an HTTP request using the provided request object and timeout.
@@ -184,40 +239,50 @@ func processRequest(request *http.Request, timeout int, retryCount int) error {
 //   - timeout: The timeout duration for the HTTP request in seconds.
 //
 // Returns:
+//   - []string: Any warnings returned by the server.
 //   - error: An error if any step of the request processing fails. Nil if the process is successful.
-func sendRequest(request *http.Request, timeout int) error {
+func sendRequest(request *http.Request, timeout int) ([]string, error) {
+	var warnings []string
+
 	client := &http.Client{
 		Timeout: time.Duration(timeout) * time.Second,
 	}
 
 	response, err := client.Do(request)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer response.Body.Close()
 
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("error reading body from response: %w", err)
+		return nil, fmt.Errorf("error reading body from response: %w", err)
 	}
 
 	contentType := response.Header.Get("Content-Type")
 
 	if strings.Contains(contentType, "application/json") {
-		warnings, err := utils.CheckResponseWarnings(responseBody)
+		responseWarnings, err := utils.CheckResponseWarnings(responseBody)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		for _, warning := range warnings {
+		for _, warning := range responseWarnings {
 			log.Warn(warning.(string))
+			warnings = append(warnings, warning.(string))
 		}
 	}
 
 	statusOK := response.StatusCode >= 200 && response.StatusCode < 300
 	if !statusOK {
-		return fmt.Errorf("%s: %s", response.Status, string(responseBody))
+		return warnings, &UploadError{
+			StatusCode:   response.StatusCode,
+			Status:       response.Status,
+			ResponseBody: string(responseBody),
+			Endpoint:     request.URL.String(),
+			RetryAfter:   response.Header.Get("Retry-After"),
+		}
 	}
 
-	return nil
+	return warnings, nil
 }
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryBaseDelay is the delay before the first retry, which is doubled for each subsequent attempt
+const retryBaseDelay = time.Second
+
+// isRetryable reports whether a failed request might succeed if it is sent again.
+// Timeouts, dropped connections and server-side/throttling responses are retried, while other
+// client errors (such as 400, 401 or 422), certificate errors and malformed URLs will never
+// succeed and are not.
+//
+// Parameters:
+//   - err: The error returned when sending the request.
+//
+// Returns:
+//   - bool: True if the request should be retried.
+func isRetryable(err error) bool {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		return isRetryableStatus(uploadErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if isCertificateError(err) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary
+	}
+
+	// Connections that are refused or reset may succeed once the server or network recovers
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isCertificateError reports whether a request failed because the server's certificate couldn't
+// be verified, or the server rejected the TLS handshake, which retrying won't fix.
+func isCertificateError(err error) bool {
+	var verificationErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	var recordHeaderErr tls.RecordHeaderError
+
+	if errors.As(err, &verificationErr) || errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &recordHeaderErr) {
+		return true
+	}
+
+	// TLS alerts sent by the server, such as a rejected client certificate
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error"
+}
+
+// isRetryableStatus reports whether a response with the given status code is worth retrying.
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay works out how long to wait before the next attempt of a request.
+// A Retry-After header from the server is honoured, otherwise an exponential backoff
+// with jitter is used. The delay is never longer than the configured maximum.
+//
+// Parameters:
+//   - attempt: The number of attempts made so far (starting from 1).
+//   - err: The error returned by the last attempt.
+//   - maxDelay: The longest time to wait. Values below zero are treated as zero.
+//
+// Returns:
+//   - time.Duration: The time to wait before the next attempt.
+func retryDelay(attempt int, err error, maxDelay time.Duration) time.Duration {
+	if maxDelay < 0 {
+		maxDelay = 0
+	}
+
+	var delay time.Duration
+
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) && uploadErr.RetryAfter != "" {
+		delay = parseRetryAfter(uploadErr.RetryAfter)
+	}
+
+	if delay <= 0 {
+		backoff := retryBaseDelay << (attempt - 1)
+		if backoff <= 0 || backoff > maxDelay {
+			backoff = maxDelay
+		}
+
+		// Wait somewhere between half and all of the backoff, so that parallel uploads don't retry in lockstep
+		delay = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// parseRetryAfter converts the value of a Retry-After header, given either as a number of
+// seconds or as an HTTP date, into a duration. Zero is returned if the value can't be parsed.
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
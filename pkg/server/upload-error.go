@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+)
+
+// UploadError is returned when the server rejects a request with a non-2xx status code
+type UploadError struct {
+	StatusCode   int
+	Status       string
+	ResponseBody string
+	Endpoint     string
+	File         string
+	RetryAfter   string
+}
+
+func (e *UploadError) Error() string {
+	return e.Status + ": " + e.ResponseBody
+}
+
+// IsDuplicate reports whether the error is the server rejecting a file that has already been uploaded
+func IsDuplicate(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+// IsNotFound reports whether the error is the server not recognising the endpoint, e.g. an
+// On-Premise installation that predates it
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// hasStatusCode reports whether the error is an UploadError with the given status code
+func hasStatusCode(err error, statusCode int) bool {
+	var uploadErr *UploadError
+
+	return errors.As(err, &uploadErr) && uploadErr.StatusCode == statusCode
+}
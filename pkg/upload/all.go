@@ -1,6 +1,11 @@
 package upload
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
@@ -17,6 +22,8 @@ func All(
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	apiKey string,
 	dryRun bool,
@@ -28,7 +35,7 @@ func All(
 	fileList, err := utils.BuildFileList(paths)
 
 	if err != nil {
-		log.Error(" error building file list", 1)
+		return fmt.Errorf("error building file list: %w", err)
 	}
 
 	log.Info("File list built..")
@@ -46,24 +53,41 @@ func All(
 		uploadOptions[key] = value
 	}
 
-	for _, file := range fileList {
+	fileNameField := "file"
 
-		fileFieldData := make(map[string]string)
+	if uploadOptions["fileNameField"] != "" {
+		fileNameField = uploadOptions["fileNameField"]
+		delete(uploadOptions, "fileNameField")
+	}
+
+	var tasks []func() error
+	var apkFiles []string
 
-		if uploadOptions["fileNameField"] != "" {
-			fileFieldData[uploadOptions["fileNameField"]] = file
-			delete(uploadOptions, "fileNameField")
-		} else {
-			fileFieldData["file"] = file
+	for _, file := range fileList {
+		// APKs are processed to upload the NDK symbol files they contain, rather than uploaded as they are
+		if filepath.Ext(file) == ".apk" {
+			log.Info("Detected APK " + file)
+			apkFiles = append(apkFiles, file)
+			continue
 		}
 
-		err := server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, file, dryRun)
+		log.DiscoverFile(file, nil)
 
-		if err != nil {
+		fileFieldData := make(map[string]string)
+		fileFieldData[fileNameField] = file
 
-			return err
-		}
+		file := file
+		tasks = append(tasks, func() error {
+			return server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, file, dryRun)
+		})
+	}
+
+	err = server.ProcessConcurrently(concurrency, tasks)
+
+	if len(apkFiles) > 0 {
+		apkErr := ProcessAndroidApk(apiKey, "", "", false, "", apkFiles, "", "", "", endpoint, retries, timeout, retryMaxDelay, concurrency, overwrite, dryRun)
+		err = errors.Join(err, apkErr)
 	}
 
-	return nil
+	return err
 }
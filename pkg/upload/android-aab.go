@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type AndroidAabMapping struct {
@@ -31,6 +33,8 @@ func ProcessAndroidAab(
 	endpoint string,
 	retries int,
 	timeout int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	dryRun bool,
 ) error {
@@ -78,15 +82,19 @@ func ProcessAndroidAab(
 				manifestData["apiKey"],
 				manifestData["applicationId"],
 				"",
-				"",
 				soFileList,
 				projectRoot,
 				"",
+				false,
 				manifestData["versionCode"],
 				manifestData["versionName"],
+				"",
+				false,
 				endpoint,
 				retries,
 				timeout,
+				retryMaxDelay,
+				concurrency,
 				overwrite,
 				dryRun,
 			)
@@ -104,7 +112,15 @@ func ProcessAndroidAab(
 	mappingFilePath := filepath.Join(aabDir, "BUNDLE-METADATA", "com.android.tools.build.obfuscation", "proguard.map")
 
 	if utils.FileExists(mappingFilePath) {
-		err = ProcessAndroidProguard(
+		workingDir, err := os.MkdirTemp("", "bugsnag-cli-proguard-*")
+
+		if err != nil {
+			return fmt.Errorf("error creating temporary working directory: %w", err)
+		}
+
+		defer os.RemoveAll(workingDir)
+
+		tasks, err := proguardUploadTasks(
 			manifestData["apiKey"],
 			manifestData["applicationId"],
 			"",
@@ -113,18 +129,69 @@ func ProcessAndroidAab(
 			[]string{filepath.Join(aabDir, "base", "dex")},
 			[]string{mappingFilePath},
 			"",
+			false,
 			manifestData["versionCode"],
 			manifestData["versionName"],
 			endpoint,
 			retries,
 			timeout,
+			retryMaxDelay,
 			overwrite,
 			dryRun,
+			workingDir,
 		)
 
 		if err != nil {
 			return err
 		}
+
+		// The mapping file covers every module in the bundle, but when the build UUID comes from the dex files each
+		// dynamic feature module has its own, so the mapping file is also uploaded for the build UUID of each feature
+		if buildUuid == "" && !noBuildUuid && manifestData["buildUuid"] == android.GetDexBuildId(filepath.Join(aabDir, "base", "dex")) {
+			for _, module := range android.GetAabFeatureModules(aabDir) {
+				moduleDexDir := filepath.Join(aabDir, module, "dex")
+				moduleBuildUuid := android.GetDexBuildId(moduleDexDir)
+
+				if moduleBuildUuid == "" {
+					continue
+				}
+
+				log.Info("Using " + moduleBuildUuid + " as build ID for the " + module + " module from dex signatures")
+
+				moduleTasks, err := proguardUploadTasks(
+					manifestData["apiKey"],
+					manifestData["applicationId"],
+					"",
+					moduleBuildUuid,
+					false,
+					[]string{moduleDexDir},
+					[]string{mappingFilePath},
+					"",
+					false,
+					manifestData["versionCode"],
+					manifestData["versionName"],
+					endpoint,
+					retries,
+					timeout,
+					retryMaxDelay,
+					overwrite,
+					dryRun,
+					workingDir,
+				)
+
+				if err != nil {
+					return err
+				}
+
+				tasks = append(tasks, moduleTasks...)
+			}
+		}
+
+		err = server.ProcessConcurrently(concurrency, tasks)
+
+		if err != nil {
+			return err
+		}
 	} else {
 		log.Info("No Proguard (mapping.txt) file detected for upload.")
 	}
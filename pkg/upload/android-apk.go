@@ -0,0 +1,178 @@
+package upload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+type AndroidApkMapping struct {
+	ApplicationId string      `help:"Module application identifier"`
+	BuildUuid     string      `help:"Module Build UUID" xor:"no-build-uuid,build-uuid"`
+	NoBuildUuid   bool        `help:"Upload with no Build UUID" xor:"build-uuid,no-build-uuid"`
+	MappingFile   utils.Path  `help:"Path to the Proguard/R8 mapping file (mapping.txt) produced alongside the APK" type:"path"`
+	Path          utils.Paths `arg:"" name:"path" help:"(required) Path to the APK file to upload" type:"path"`
+	ProjectRoot   string      `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
+	VersionCode   string      `help:"Module version code"`
+	VersionName   string      `help:"Module version name"`
+}
+
+func ProcessAndroidApk(
+	apiKey string,
+	applicationId string,
+	buildUuid string,
+	noBuildUuid bool,
+	mappingFile string,
+	paths []string,
+	projectRoot string,
+	versionCode string,
+	versionName string,
+	endpoint string,
+	retries int,
+	timeout int,
+	retryMaxDelay time.Duration,
+	concurrency int,
+	overwrite bool,
+	dryRun bool,
+) error {
+
+	for _, path := range paths {
+		if filepath.Ext(path) != ".apk" || utils.IsDir(path) {
+			return fmt.Errorf(path + " is not an APK file")
+		}
+
+		err := processAndroidApkFile(
+			apiKey,
+			applicationId,
+			buildUuid,
+			noBuildUuid,
+			mappingFile,
+			path,
+			projectRoot,
+			versionCode,
+			versionName,
+			endpoint,
+			retries,
+			timeout,
+			retryMaxDelay,
+			concurrency,
+			overwrite,
+			dryRun,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processAndroidApkFile - Uploads the symbol files of a single APK, removing the directory it was extracted into once
+// its uploads have finished
+func processAndroidApkFile(
+	apiKey string,
+	applicationId string,
+	buildUuid string,
+	noBuildUuid bool,
+	mappingFile string,
+	path string,
+	projectRoot string,
+	versionCode string,
+	versionName string,
+	endpoint string,
+	retries int,
+	timeout int,
+	retryMaxDelay time.Duration,
+	concurrency int,
+	overwrite bool,
+	dryRun bool,
+) error {
+	log.Info("Extracting " + filepath.Base(path) + " into a temporary directory")
+	apkDir, err := utils.ExtractFile(path, "apk")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(apkDir)
+
+	manifestData, err := android.MergeUploadOptionsFromApkManifest(apkDir, apiKey, applicationId, buildUuid, noBuildUuid, versionCode, versionName)
+
+	if err != nil {
+		return err
+	}
+
+	soFilePath := filepath.Join(apkDir, "lib")
+
+	if utils.FileExists(soFilePath) {
+		soFileList, err := utils.BuildFileList([]string{soFilePath})
+
+		if err != nil {
+			return err
+		}
+
+		err = ProcessAndroidNDK(
+			manifestData["apiKey"],
+			manifestData["applicationId"],
+			"",
+			soFileList,
+			projectRoot,
+			"",
+			false,
+			manifestData["versionCode"],
+			manifestData["versionName"],
+			"",
+			false,
+			endpoint,
+			retries,
+			timeout,
+			retryMaxDelay,
+			concurrency,
+			overwrite,
+			dryRun,
+		)
+
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Info("No NDK (.so) files detected for upload.")
+	}
+
+	if mappingFile != "" {
+		err = ProcessAndroidProguard(
+			manifestData["apiKey"],
+			manifestData["applicationId"],
+			"",
+			manifestData["buildUuid"],
+			noBuildUuid,
+			[]string{apkDir},
+			[]string{mappingFile},
+			"",
+			false,
+			manifestData["versionCode"],
+			manifestData["versionName"],
+			endpoint,
+			retries,
+			timeout,
+			retryMaxDelay,
+			concurrency,
+			overwrite,
+			dryRun,
+		)
+
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Info("No Proguard (mapping.txt) file given, only NDK files will be uploaded.")
+	}
+
+	return nil
+}
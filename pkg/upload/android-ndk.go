@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -13,28 +14,35 @@ import (
 
 type AndroidNdkMapping struct {
 	ApplicationId  string      `help:"Module application identifier"`
-	AndroidNdkRoot string      `help:"Path to Android NDK installation ($ANDROID_NDK_ROOT)"`
+	AndroidNdkRoot string      `help:"Path to Android NDK installation ($ANDROID_NDK_ROOT). No longer required, as debug info is extracted without the NDK" hidden:""`
 	AppManifest    string      `help:"Path to app manifest file" type:"path"`
 	Path           utils.Paths `arg:"" name:"path" help:"Path to directory or file to upload" type:"path" default:"."`
 	ProjectRoot    string      `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
-	Variant        string      `help:"Build type, like 'debug' or 'release'"`
+	Variant        string      `help:"Build variant, like 'release' or 'freeRelease', optionally with its module, like 'wear:release'"`
+	AllVariants    bool        `help:"Upload the symbol files of every variant of every application module found"`
 	VersionCode    string      `help:"Module version code"`
 	VersionName    string      `help:"Module version name"`
+	VerifyAgainst  utils.Path  `help:"Path to the shipped .so, .apk or .aab to check that the symbol files match before uploading" type:"path"`
+	VerifyWarnOnly bool        `help:"Warn instead of failing when the symbol files don't match the binary given with --verify-against"`
 }
 
 func ProcessAndroidNDK(
 	apiKey string,
 	applicationId string,
-	androidNdkRoot string,
 	appManifestPath string,
 	paths []string,
 	projectRoot string,
 	variant string,
+	allVariants bool,
 	versionCode string,
 	versionName string,
+	verifyAgainst string,
+	verifyWarnOnly bool,
 	endpoint string,
 	retries int,
 	timeout int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	dryRun bool,
 ) error {
@@ -45,41 +53,74 @@ func ProcessAndroidNDK(
 	var err error
 	var workingDir string
 	var appManifestPathExpected string
-	var objCopyPath string
 
 	for _, path := range paths {
 		if utils.IsDir(path) {
-			mergeNativeLibPath = filepath.Join(path, "app", "build", "intermediates", "merged_native_libs")
+			variants, err := android.FindProjectVariants(path, filepath.Join("intermediates", "merged_native_libs"), "out", variant, allVariants)
 
-			// Check to see if we can find app/build/intermediates/merged_native_libs from the given path
-			if !utils.FileExists(mergeNativeLibPath) {
-				return fmt.Errorf("unable to find the merged_native_libs in " + path)
+			if err != nil {
+				return err
 			}
 
-			if variant == "" {
-				variant, err = android.GetVariantDirectory(mergeNativeLibPath)
+			for _, projectVariant := range variants {
+				if len(variants) > 1 {
+					log.Info("Processing variant " + projectVariant.String())
+				}
+
+				variantFileList, err := utils.BuildFileList([]string{projectVariant.Path})
 
 				if err != nil {
-					return err
+					return fmt.Errorf("error building file list for variant %s: %w", projectVariant, err)
 				}
-			}
 
-			fileList, err = utils.BuildFileList([]string{filepath.Join(mergeNativeLibPath, variant)})
+				if appMetadata, err := android.ReadAppMetadata(projectVariant.BuildDir, projectVariant.Name); err == nil && appMetadata["androidGradlePluginVersion"] != "" {
+					log.Info("Built with Android Gradle Plugin " + appMetadata["androidGradlePluginVersion"])
+				}
 
-			if err != nil {
-				return fmt.Errorf("error building file list for variant: " + variant + ". " + err.Error())
-			}
+				variantApplicationId := applicationId
+				variantVersionCode := versionCode
+				variantVersionName := versionName
+				variantManifestPath := appManifestPath
+
+				if variantManifestPath == "" {
+					variantManifestPath = android.FindVariantManifest(projectVariant.BuildDir, projectVariant.Name)
+					if variantManifestPath != "" {
+						log.Info("Found app manifest at: " + variantManifestPath)
+					} else {
+						variantApplicationId, variantVersionCode, variantVersionName = android.MergeVariantOutputVersion(projectVariant.BuildDir, projectVariant.Name, applicationId, versionCode, versionName)
+					}
+				}
 
-			if appManifestPath == "" {
-				appManifestPathExpected = filepath.Join(path, "app", "build", "intermediates", "merged_manifests", variant, "AndroidManifest.xml")
-				if utils.FileExists(appManifestPathExpected) {
-					appManifestPath = appManifestPathExpected
-					log.Info("Found app manifest at: " + appManifestPath)
+				variantProjectRoot := projectRoot
+				if variantProjectRoot == "" {
+					variantProjectRoot = path
 				}
-			}
 
-			if projectRoot == "" {
-				projectRoot = path
+				// Each variant is uploaded with the version and API key from its own manifest
+				err = ProcessAndroidNDK(
+					apiKey,
+					variantApplicationId,
+					variantManifestPath,
+					variantFileList,
+					variantProjectRoot,
+					projectVariant.Name,
+					false,
+					variantVersionCode,
+					variantVersionName,
+					verifyAgainst,
+					verifyWarnOnly,
+					endpoint,
+					retries,
+					timeout,
+					retryMaxDelay,
+					concurrency,
+					overwrite,
+					dryRun,
+				)
+
+				if err != nil {
+					return err
+				}
 			}
 
 		} else {
@@ -87,18 +128,17 @@ func ProcessAndroidNDK(
 
 			if appManifestPath == "" {
 				if variant == "" {
-					//	Set the mergeNativeLibPath based off the file location e.g. merged_native_libs/<variant/out/lib/<arch>/
+					//	Set the mergeNativeLibPath based off the file location e.g. merged_native_libs/<variant>/out/lib/<arch>/
 					mergeNativeLibPath = filepath.Join(path, "..", "..", "..", "..", "..")
 
 					if filepath.Base(mergeNativeLibPath) == "merged_native_libs" {
-						variant, err = android.GetVariantDirectory(mergeNativeLibPath)
-
-						if err == nil {
-							appManifestPathExpected = filepath.Join(mergeNativeLibPath, "..", "merged_manifests", variant, "AndroidManifest.xml")
-							if utils.FileExists(appManifestPathExpected) {
-								appManifestPath = appManifestPathExpected
-								log.Info("Found app manifest at: " + appManifestPath)
-							}
+						// The variant is known from the file location, even when other variants have been built
+						variant = filepath.Base(filepath.Join(path, "..", "..", "..", ".."))
+
+						appManifestPathExpected = android.FindVariantManifest(filepath.Join(mergeNativeLibPath, "..", ".."), variant)
+						if appManifestPathExpected != "" {
+							appManifestPath = appManifestPathExpected
+							log.Info("Found app manifest at: " + appManifestPath)
 						}
 
 						if projectRoot == "" {
@@ -111,6 +151,10 @@ func ProcessAndroidNDK(
 		}
 	}
 
+	if len(fileList) == 0 {
+		return nil
+	}
+
 	if projectRoot != "" {
 		log.Info("Using " + projectRoot + " as the project root")
 	}
@@ -133,6 +177,7 @@ func ProcessAndroidNDK(
 			}
 
 			if apiKey != "" {
+				log.AddSecret(apiKey)
 				log.Info("Using " + apiKey + " as API key from AndroidManifest.xml")
 
 			}
@@ -163,29 +208,14 @@ func ProcessAndroidNDK(
 		}
 	}
 
-	// Process .so files through objcopy to create .sym files, filtering any other file type
+	// Extract the debug info from .so files to create .sym files, filtering any other file type
 	for _, file := range fileList {
 		if strings.HasSuffix(file, ".so.sym") {
+			log.DiscoverFile(file, nil)
 			symbolFileList = append(symbolFileList, file)
 		} else if filepath.Ext(file) == ".so" {
-			// Check NDK path is set
-			if objCopyPath == "" {
-				androidNdkRoot, err = android.GetAndroidNDKRoot(androidNdkRoot)
-
-				if err != nil {
-					return err
-				}
-
-				objCopyPath, err = android.BuildObjcopyPath(androidNdkRoot)
-
-				if err != nil {
-					return err
-				}
-
-				log.Info("Located objcopy within Android NDK path: " + androidNdkRoot)
-			}
-
-			log.Info("Extracting debug info from " + filepath.Base(file) + " using objcopy")
+			log.DiscoverFile(file, nil)
+			log.Info("Extracting debug info from " + filepath.Base(file))
 
 			if workingDir == "" {
 				workingDir, err = os.MkdirTemp("", "bugsnag-cli-ndk-*")
@@ -197,16 +227,36 @@ func ProcessAndroidNDK(
 				defer os.RemoveAll(workingDir)
 			}
 
-			outputFile, err := android.Objcopy(objCopyPath, file, workingDir)
+			outputFile, err := android.ExtractDebugInfo(file, workingDir)
 
 			if err != nil {
-				return fmt.Errorf("failed to process file, " + file + " using objcopy : " + err.Error())
+				return fmt.Errorf("failed to process file %s: %w", file, err)
 			}
 
+			log.AliasFile(outputFile, file)
 			symbolFileList = append(symbolFileList, outputFile)
 		}
 	}
 
+	symbolIds := make(map[string]string)
+	for _, file := range fileList {
+		if strings.HasSuffix(file, ".so.sym") || filepath.Ext(file) == ".so" {
+			// Files whose build ID can't be read are checked with an empty build ID, so that they are reported
+			elfInfo, err := android.GetElfInfo(file)
+			if err == nil {
+				symbolIds[file] = elfInfo.BuildId
+			} else {
+				symbolIds[file] = ""
+			}
+		}
+	}
+
+	err = VerifySymbolFiles(symbolIds, verifyAgainst, verifyWarnOnly)
+
+	if err != nil {
+		return err
+	}
+
 	err = android.UploadAndroidNdk(
 		symbolFileList,
 		apiKey,
@@ -218,6 +268,8 @@ func ProcessAndroidNDK(
 		endpoint,
 		timeout,
 		retries,
+		retryMaxDelay,
+		concurrency,
 		dryRun,
 	)
 
@@ -2,8 +2,10 @@ package upload
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -18,7 +20,8 @@ type AndroidProguardMapping struct {
 	NoBuildUuid   bool        `help:"Upload with no Build UUID" xor:"build-uuid,no-build-uuid"`
 	DexFiles      []string    `help:"Path to classes.dex files or directory" type:"path" default:""`
 	Path          utils.Paths `arg:"" name:"path" help:"Path to directory or file to upload" type:"path" default:"."`
-	Variant       string      `help:"Build type, like 'debug' or 'release'"`
+	Variant       string      `help:"Build variant, like 'release' or 'freeRelease', optionally with its module, like 'wear:release'"`
+	AllVariants   bool        `help:"Upload the mapping files of every variant of every application module found"`
 	VersionCode   string      `help:"Module version code"`
 	VersionName   string      `help:"Module version name"`
 }
@@ -32,71 +35,253 @@ func ProcessAndroidProguard(
 	dexFiles []string,
 	paths []string,
 	variant string,
+	allVariants bool,
 	versionCode string,
 	versionName string,
 	endpoint string,
 	retries int,
 	timeout int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	dryRun bool,
 ) error {
+	workingDir, err := os.MkdirTemp("", "bugsnag-cli-proguard-*")
 
+	if err != nil {
+		return fmt.Errorf("error creating temporary working directory: %w", err)
+	}
+
+	defer os.RemoveAll(workingDir)
+
+	tasks, err := proguardUploadTasks(
+		apiKey,
+		applicationId,
+		appManifestPath,
+		buildUuid,
+		noBuildUuid,
+		dexFiles,
+		paths,
+		variant,
+		allVariants,
+		versionCode,
+		versionName,
+		endpoint,
+		retries,
+		timeout,
+		retryMaxDelay,
+		overwrite,
+		dryRun,
+		workingDir,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return server.ProcessConcurrently(concurrency, tasks)
+}
+
+// proguardUploadTasks - Finds the mapping files to upload along with their options, compressing each into the working
+// directory, and returns the tasks that upload them so that they can be run concurrently
+func proguardUploadTasks(
+	apiKey string,
+	applicationId string,
+	appManifestPath string,
+	buildUuid string,
+	noBuildUuid bool,
+	dexFiles []string,
+	paths []string,
+	variant string,
+	allVariants bool,
+	versionCode string,
+	versionName string,
+	endpoint string,
+	retries int,
+	timeout int,
+	retryMaxDelay time.Duration,
+	overwrite bool,
+	dryRun bool,
+	workingDir string,
+) ([]func() error, error) {
+
+	var tasks []func() error
 	var mappingFile string
 	var appManifestPathExpected string
-	var err error
+
+	// Each path is processed separately, so that the options found for one mapping file aren't used for the next
+	if len(paths) > 1 {
+		for _, path := range paths {
+			pathTasks, err := proguardUploadTasks(
+				apiKey,
+				applicationId,
+				appManifestPath,
+				buildUuid,
+				noBuildUuid,
+				dexFiles,
+				[]string{path},
+				variant,
+				allVariants,
+				versionCode,
+				versionName,
+				endpoint,
+				retries,
+				timeout,
+				retryMaxDelay,
+				overwrite,
+				dryRun,
+				workingDir,
+			)
+
+			if err != nil {
+				return nil, err
+			}
+
+			tasks = append(tasks, pathTasks...)
+		}
+
+		return tasks, nil
+	}
 
 	for _, path := range paths {
 		if utils.IsDir(path) {
+			variants, err := android.FindProjectVariants(path, filepath.Join("outputs", "mapping"), "mapping.txt", variant, allVariants)
 
-			mappingPath := filepath.Join(path, "app", "build", "outputs", "mapping")
-
-			if !utils.FileExists(mappingPath) {
-				return fmt.Errorf("unable to find the mapping directory in " + path)
+			if err != nil {
+				return nil, err
 			}
 
-			if variant == "" {
-				variant, err = android.GetVariantDirectory(mappingPath)
+			for _, projectVariant := range variants {
+				if len(variants) > 1 {
+					log.Info("Processing variant " + projectVariant.String())
+				}
+
+				if appMetadata, err := android.ReadAppMetadata(projectVariant.BuildDir, projectVariant.Name); err == nil && appMetadata["androidGradlePluginVersion"] != "" {
+					log.Info("Built with Android Gradle Plugin " + appMetadata["androidGradlePluginVersion"])
+				}
+
+				variantApplicationId := applicationId
+				variantVersionCode := versionCode
+				variantVersionName := versionName
+				variantManifestPath := appManifestPath
+
+				if variantManifestPath == "" {
+					variantManifestPath = android.FindVariantManifest(projectVariant.BuildDir, projectVariant.Name)
+					if variantManifestPath != "" {
+						log.Info("Found app manifest at: " + variantManifestPath)
+					} else {
+						variantApplicationId, variantVersionCode, variantVersionName = android.MergeVariantOutputVersion(projectVariant.BuildDir, projectVariant.Name, applicationId, versionCode, versionName)
+					}
+				}
+
+				variantDexFiles := dexFiles
+				if len(variantDexFiles) == 0 {
+					variantDexFiles = android.FindBuildDexFiles(projectVariant.BuildDir, projectVariant.Name)
+				}
+
+				// Each variant is uploaded with the version, API key and build UUID from its own build
+				variantTasks, err := proguardUploadTasks(
+					apiKey,
+					variantApplicationId,
+					variantManifestPath,
+					buildUuid,
+					noBuildUuid,
+					variantDexFiles,
+					[]string{filepath.Join(projectVariant.Path, "mapping.txt")},
+					projectVariant.Name,
+					false,
+					variantVersionCode,
+					variantVersionName,
+					endpoint,
+					retries,
+					timeout,
+					retryMaxDelay,
+					overwrite,
+					dryRun,
+					workingDir,
+				)
 
 				if err != nil {
-					return err
+					return nil, err
 				}
-			}
 
-			mappingFile = filepath.Join(mappingPath, variant, "mapping.txt")
+				tasks = append(tasks, variantTasks...)
 
-			if !utils.FileExists(mappingFile) {
-				return fmt.Errorf("unable to find mapping file in the specified project directory")
-			}
+				// The mapping file covers the app's dynamic feature modules too, but when the build UUID comes from the
+				// dex files each feature module has its own, so the mapping file is also uploaded for each of them
+				if projectVariant.Feature != "" || buildUuid != "" || noBuildUuid || variantManifestPath == "" || android.GetManifestBuildUuid(variantManifestPath) != "" {
+					continue
+				}
 
-			if appManifestPath == "" {
-				appManifestPathExpected = filepath.Join(path, "app", "build", "intermediates", "merged_manifests", variant, "AndroidManifest.xml")
-				if utils.FileExists(appManifestPathExpected) {
-					appManifestPath = appManifestPathExpected
-					log.Info("Found app manifest at: " + appManifestPath)
+				features, err := android.FindFeatureVariants(path, projectVariant.Name)
+
+				if err != nil {
+					return nil, err
 				}
-			}
 
-		} else {
-			mappingFile = path
-
-			if appManifestPath == "" {
-				if variant == "" {
-					//	Set the mergeNativeLibPath based off the file location e.g. outputs/mapping/<variant>/mapping.txt
-					mergedManifestPath := filepath.Join(path, "..", "..", "..", "..", "intermediates", "merged_manifests")
-
-					if filepath.Base(mergedManifestPath) == "merged_manifests" {
-						variant, err = android.GetVariantDirectory(mergedManifestPath)
-						if err == nil {
-							appManifestPathExpected = filepath.Join(mergedManifestPath, variant, "AndroidManifest.xml")
-							if utils.FileExists(appManifestPathExpected) {
-								appManifestPath = appManifestPathExpected
-								log.Info("Found app manifest at: " + appManifestPath)
-							}
+				for _, feature := range features {
+					featureDexFiles := android.FindBuildDexFiles(feature.BuildDir, feature.Name)
+					featureBuildUuid := ""
+
+					if dexFiles, err := android.GetDexFiles(featureDexFiles); err == nil && len(dexFiles) > 0 {
+						if signature, err := android.GetAppSignatureFromFiles(dexFiles); err == nil {
+							featureBuildUuid = fmt.Sprintf("%x", signature)
 						}
 					}
+
+					if featureBuildUuid == "" {
+						continue
+					}
+
+					log.Info("Using " + featureBuildUuid + " as build ID for the " + feature.Module + " module from dex signatures")
+
+					featureTasks, err := proguardUploadTasks(
+						apiKey,
+						variantApplicationId,
+						variantManifestPath,
+						featureBuildUuid,
+						false,
+						featureDexFiles,
+						[]string{filepath.Join(projectVariant.Path, "mapping.txt")},
+						projectVariant.Name,
+						false,
+						variantVersionCode,
+						variantVersionName,
+						endpoint,
+						retries,
+						timeout,
+						retryMaxDelay,
+						overwrite,
+						dryRun,
+						workingDir,
+					)
+
+					if err != nil {
+						return nil, err
+					}
+
+					tasks = append(tasks, featureTasks...)
 				}
 			}
 
+			continue
+		}
+
+		mappingFile = path
+
+		if appManifestPath == "" {
+			if variant == "" {
+				// The variant is known from the file location e.g. outputs/mapping/<variant>/mapping.txt
+				if filepath.Base(filepath.Join(path, "..", "..")) == "mapping" {
+					variant = filepath.Base(filepath.Dir(path))
+
+					appManifestPathExpected = android.FindVariantManifest(filepath.Join(path, "..", "..", "..", ".."), variant)
+					if appManifestPathExpected != "" {
+						appManifestPath = appManifestPathExpected
+						log.Info("Found app manifest at: " + appManifestPath)
+					}
+				}
+			}
 		}
 
 		// Check to see if we need to read the manifest file due to missing options
@@ -106,7 +291,7 @@ func ProcessAndroidProguard(
 			manifestData, err := android.ParseAndroidManifestXML(appManifestPath)
 
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			if apiKey == "" {
@@ -117,6 +302,7 @@ func ProcessAndroidProguard(
 				}
 
 				if apiKey != "" {
+					log.AddSecret(apiKey)
 					log.Info("Using " + apiKey + " as API key from AndroidManifest.xml")
 				}
 			}
@@ -146,12 +332,12 @@ func ProcessAndroidProguard(
 				if buildUuid == "" && len(dexFiles) > 0 {
 					safeDexFile, err := android.GetDexFiles(dexFiles)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					signature, err := android.GetAppSignatureFromFiles(safeDexFile)
 					if err != nil {
-						return err
+						return nil, err
 					}
 
 					buildUuid = fmt.Sprintf("%x", signature)
@@ -159,7 +345,7 @@ func ProcessAndroidProguard(
 					if buildUuid != "" {
 						log.Info("Using " + buildUuid + " as build ID from classes.dex")
 					}
-				} else {
+				} else if buildUuid != "" {
 					log.Info("Using " + buildUuid + " as build UUID from AndroidManifest.xml")
 				}
 			}
@@ -181,35 +367,69 @@ func ProcessAndroidProguard(
 			}
 		}
 
+		mappingInfo, err := android.ReadMappingFile(mappingFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", mappingFile, err)
+		}
+
+		log.DiscoverFile(mappingFile, map[string]string{"mapId": mappingInfo.MapId})
+
+		err = mappingInfo.Validate()
+
+		if err != nil {
+			return nil, fmt.Errorf("refusing to upload %s: %w", mappingFile, err)
+		}
+
+		if warning := mappingInfo.InvalidLineWarning(); warning != "" {
+			log.Warn(filepath.Base(mappingFile) + " " + warning)
+		}
+
+		if mappingInfo.Compiler != "" {
+			log.Info(filepath.Base(mappingFile) + " was written by " + strings.TrimSpace(mappingInfo.Compiler+" "+mappingInfo.CompilerVersion))
+		}
+
+		if mappingInfo.MapId != "" {
+			log.Info("Using " + mappingInfo.MapId + " as map ID from " + filepath.Base(mappingFile))
+		}
+
 		log.Info("Compressing " + mappingFile)
 
-		outputFile, err := utils.GzipCompress(mappingFile)
+		// Each mapping file is compressed into its own directory, as mapping files from different variants share a name
+		outputDir, err := os.MkdirTemp(workingDir, "mapping-*")
 
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("error creating temporary working directory: %w", err)
 		}
 
-		uploadOptions, err := utils.BuildAndroidProguardUploadOptions(apiKey, applicationId, versionName, versionCode, buildUuid, overwrite)
+		outputFile, err := utils.GzipCompressToDir(mappingFile, outputDir)
 
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		log.AliasFile(outputFile, mappingFile)
+
+		uploadOptions, err := utils.BuildAndroidProguardUploadOptions(apiKey, applicationId, versionName, versionCode, buildUuid, mappingInfo.MapId, overwrite)
+
+		if err != nil {
+			return nil, err
 		}
 
 		fileFieldData := make(map[string]string)
 		fileFieldData["proguard"] = outputFile
 
-		err = server.ProcessFileRequest(endpoint+"/proguard", uploadOptions, fileFieldData, timeout, retries, outputFile, dryRun)
+		tasks = append(tasks, func() error {
+			err := server.ProcessFileRequest(endpoint+"/proguard", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, outputFile, dryRun)
 
-		if err != nil {
-			if strings.Contains(err.Error(), "404 Not Found") {
+			if err != nil && server.IsNotFound(err) {
 				log.Info("Trying " + endpoint)
-				err = server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, outputFile, dryRun)
+				err = server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, outputFile, dryRun)
 			}
-		}
 
-		if err != nil {
 			return err
-		}
+		})
 	}
-	return nil
+
+	return tasks, nil
 }
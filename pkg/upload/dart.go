@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
 	"github.com/bugsnag/bugsnag-cli/pkg/server"
 	"github.com/bugsnag/bugsnag-cli/pkg/utils"
@@ -19,11 +21,13 @@ var androidSymbolFileRegex = regexp.MustCompile("android-([^;]*).symbols")
 var iosSymbolFileRegex = regexp.MustCompile("ios-([^;]*).symbols")
 
 type DartSymbolOptions struct {
-	Path          utils.Paths `arg:"" name:"path" help:"(required) Path to directory or file to upload" type:"path"`
-	IosAppPath    utils.Path  `help:"(optional) the path to the built iOS app." type:"path"`
-	VersionName   string      `help:"The version of the application." xor:"app-version,version-name"`
-	VersionCode   string      `help:"The version code for the application (Android only)." xor:"app-version-code,version-code"`
-	BundleVersion string      `help:"The bundle version for the application (iOS only)." xor:"app-bundle-version,bundle-version"`
+	Path           utils.Paths `arg:"" name:"path" help:"(required) Path to directory or file to upload" type:"path"`
+	IosAppPath     utils.Path  `help:"(optional) the path to the built iOS app." type:"path"`
+	VersionName    string      `help:"The version of the application." xor:"app-version,version-name"`
+	VersionCode    string      `help:"The version code for the application (Android only)." xor:"app-version-code,version-code"`
+	BundleVersion  string      `help:"The bundle version for the application (iOS only)." xor:"app-bundle-version,bundle-version"`
+	VerifyAgainst  utils.Path  `help:"Path to the shipped libapp.so, .apk or .aab to check that the Android symbol files match before uploading" type:"path"`
+	VerifyWarnOnly bool        `help:"Warn instead of failing when the symbol files don't match the binary given with --verify-against"`
 }
 
 func Dart(
@@ -32,20 +36,27 @@ func Dart(
 	versionCode string,
 	bundleVersion string,
 	iosAppPath string,
+	verifyAgainst string,
+	verifyWarnOnly bool,
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	apiKey string,
 	dryRun bool,
 ) error {
 
+	var tasks []func() error
+	symbolIds := make(map[string]string)
+
 	log.Info("Building file list from path")
 
 	fileList, err := utils.BuildFileList(paths)
 
 	if err != nil {
-		log.Error("error building file list", 1)
+		return fmt.Errorf("error building file list: %w", err)
 	}
 
 	log.Info("File list built")
@@ -66,18 +77,19 @@ func Dart(
 				return err
 			}
 
+			log.DiscoverFile(file, map[string]string{"buildId": buildId, "platform": "android"})
+			symbolIds[file] = buildId
+
 			// Build Upload options
 			uploadOptions := utils.BuildDartUploadOptions(apiKey, buildId, "android", overwrite, version, versionCode)
 
 			fileFieldData := make(map[string]string)
 			fileFieldData["symbolFile"] = file
 
-			err := server.ProcessFileRequest(endpoint+"/dart-symbol", uploadOptions, fileFieldData, timeout, retries, file, dryRun)
-
-			if err != nil {
-
-				return err
-			}
+			file := file
+			tasks = append(tasks, func() error {
+				return server.ProcessFileRequest(endpoint+"/dart-symbol", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, file, dryRun)
+			})
 
 			continue
 		}
@@ -106,29 +118,33 @@ func Dart(
 				return err
 			}
 
+			log.DiscoverFile(file, map[string]string{"buildId": buildId, "arch": arch, "platform": "ios"})
+
 			// Build Upload options
 			uploadOptions := utils.BuildDartUploadOptions(apiKey, buildId, "ios", overwrite, version, bundleVersion)
 
 			fileFieldData := make(map[string]string)
 			fileFieldData["symbolFile"] = file
 
-			if dryRun {
-				err = nil
-			} else {
-				err = server.ProcessFileRequest(endpoint+"/dart-symbol", uploadOptions, fileFieldData, timeout, retries, file, dryRun)
-			}
-
-			if err != nil {
-
-				return err
+			if !dryRun {
+				file := file
+				tasks = append(tasks, func() error {
+					return server.ProcessFileRequest(endpoint+"/dart-symbol", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, file, dryRun)
+				})
 			}
 
 			continue
 		}
 		log.Info("Skipping " + file)
+		log.RecordFile(log.FileEvent{File: file, Status: log.StatusSkipped})
 	}
 
-	return nil
+	err = VerifySymbolFiles(symbolIds, verifyAgainst, verifyWarnOnly)
+	if err != nil {
+		return err
+	}
+
+	return server.ProcessConcurrently(concurrency, tasks)
 }
 
 // ReadElfFile - Gets all data from the symbol file
@@ -156,19 +172,7 @@ func GetBuildIdFromElfFile(symbolFile string) (string, error) {
 		return "", err
 	}
 
-	if sect := elfData.Section(".note.gnu.build-id"); sect != nil {
-		var data []byte
-		data, err = sect.Data()
-		if err != nil {
-			return "", fmt.Errorf("error reading symbol file")
-		}
-
-		buildId := fmt.Sprintf("%x", data[16:])
-
-		return buildId, nil
-	}
-
-	return "", fmt.Errorf("unable to find buildId")
+	return android.GetElfBuildId(elfData)
 }
 
 // GetArchFromElfFile - Gets the Arch from the symbol file to help with getting the UUID from the built iOS app
@@ -226,33 +230,19 @@ func GetIosAppPath(symbolFile string) (string, error) {
 	return "", fmt.Errorf("unable to find iOS app path, try adding --ios-app-path")
 }
 
-// DwarfDumpUuid - Gets the UUID/Build ID from the Dwarf debug info of a file for a given Arch
+// DwarfDumpUuid - Gets the UUID/Build ID from the Mach-O headers of a file for a given Arch
 func DwarfDumpUuid(symbolFile string, dwarfFile string, arch string) (string, error) {
-	dwarfDumpLocation, err := exec.LookPath("dwarfdump")
+	dwarfInfo, err := ios.GetMachOUuids(dwarfFile)
 
 	if err != nil {
-		dwarfDumpLocation, err = exec.LookPath("llvm-dwarfdump")
-
-		if err != nil {
-			return "", fmt.Errorf("unable to find dwarfdump on system: %w", err)
-		}
+		return "", fmt.Errorf("unable to read UUIDs from %s: %w", dwarfFile, err)
 	}
 
-	uuidArray := make(map[string]string)
-	cmd := exec.Command(dwarfDumpLocation, "--uuid", dwarfFile, "--arch", arch)
-	output, _ := cmd.CombinedOutput()
-	outputArray := strings.Fields(string(output))
-
-	uuidArray[outputArray[2]] = outputArray[1]
-
-	for key, value := range uuidArray {
-		uuidArch := strings.Replace(key, "(", "", -1)
-		uuidArch = strings.Replace(uuidArch, ")", "", -1)
-
-		if strings.Contains(symbolFile, uuidArch) {
-			return value, nil
+	for _, dwarf := range dwarfInfo {
+		if dwarf.Arch == arch && strings.Contains(symbolFile, dwarf.Arch) {
+			return dwarf.UUID, nil
 		}
 	}
 
-	return "", fmt.Errorf("unable to find matching UUID")
+	return "", errors.New("unable to find matching UUID")
 }
@@ -2,9 +2,10 @@ package upload
 
 import (
 	"errors"
+	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/ios"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -13,15 +14,26 @@ import (
 )
 
 type Dsym struct {
-	VersionName        string      `help:"The version of the application."`
-	Scheme             string      `help:"The name of the scheme to use when building the application."`
-	Dev                bool        `help:"Indicates whether the application is a debug or release build"`
-	XcodeProject       utils.Path  `help:"Path to the dSYM" type:"path"`
-	Plist              utils.Path  `help:"Path to the Info.plist file" type:"path"`
-	ProjectRoot        string      `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
-	IgnoreMissingDwarf bool        `help:"Throw warnings instead of errors when a dSYM with missing DWARF data is found"`
-	IgnoreEmptyDsym    bool        `help:"Throw warnings instead of errors when a *.dSYM file is found, rather than the expected *.dSYM directory"`
-	Path               utils.Paths `arg:"" name:"path" help:"Path to directory or file to upload" type:"path" default:"."`
+	VersionName        string     `help:"The version of the application."`
+	Scheme             string     `help:"The name of the scheme to use when building the application."`
+	Dev                bool       `help:"Indicates whether the application is a debug or release build"`
+	XcodeProject       utils.Path `help:"Path to the dSYM" type:"path"`
+	Plist              utils.Path `help:"Path to the Info.plist file" type:"path"`
+	ProjectRoot        string     `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
+	IgnoreMissingDwarf bool       `help:"Throw warnings instead of errors when a dSYM with missing DWARF data is found"`
+	IgnoreEmptyDsym    bool       `help:"Throw warnings instead of errors when a *.dSYM file is found, rather than the expected *.dSYM directory"`
+	PlutilFallback     bool       `help:"Use plutil to read Info.plist files that can't be read natively (macOS only)"`
+	VerifyAgainst      utils.Path `help:"Path to the shipped binary, .app or .ipa to check that the dSYMs match before uploading" type:"path"`
+	VerifyWarnOnly     bool       `help:"Warn instead of failing when the dSYMs don't match the binary given with --verify-against"`
+
+	FromAppStoreConnect       bool        `help:"Download the dSYMs of a build from App Store Connect and upload them, rather than reading them from <path>"`
+	AppStoreConnectKeyId      string      `help:"The key ID of the App Store Connect API key" env:"BUGSNAG_APP_STORE_CONNECT_KEY_ID"`
+	AppStoreConnectIssuerId   string      `help:"The issuer ID of the App Store Connect API key" env:"BUGSNAG_APP_STORE_CONNECT_ISSUER_ID"`
+	AppStoreConnectPrivateKey utils.Path  `help:"Path to the App Store Connect API private key (.p8) file" type:"path" env:"BUGSNAG_APP_STORE_CONNECT_PRIVATE_KEY"`
+	AppStoreConnectApiRootUrl string      `help:"App Store Connect API server URL" default:"https://api.appstoreconnect.apple.com" hidden:""`
+	AppId                     string      `help:"The Apple ID of the app on App Store Connect"`
+	BuildNumber               string      `help:"The build number (CFBundleVersion) of the build on App Store Connect"`
+	Path                      utils.Paths `arg:"" name:"path" help:"Path to directory, .dSYM, .zip, .xcarchive or .ipa to upload" type:"path" default:"."`
 }
 
 func ProcessDsym(
@@ -29,19 +41,23 @@ func ProcessDsym(
 	scheme string,
 	xcodeProjPath string,
 	plistPath string,
+	plutilFallback bool,
 	projectRoot string,
 	ignoreMissingDwarf bool,
 	ignoreEmptyDsym bool,
 	paths []string,
+	verifyAgainst string,
+	verifyWarnOnly bool,
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	dryRun bool,
 ) error {
 
 	var buildSettings *ios.XcodeBuildSettings
 	var plistData *ios.PlistData
-	var uploadOptions map[string]string
 
 	var dwarfInfo []*ios.DwarfInfo
 	var tempDirs []string
@@ -57,7 +73,26 @@ func ProcessDsym(
 	}()
 
 	for _, path := range paths {
-		if ios.IsPathAnXcodeProjectOrWorkspace(path) {
+		var archiveInfo *ios.ArchiveInfo
+
+		if ios.IsXcarchive(path) || ios.IsIpa(path) {
+			// Archives contain both the dSYMs and the app's Info.plist, so no Xcode project is needed
+			if ios.IsXcarchive(path) {
+				archiveInfo, err = ios.ReadXcarchive(path, plutilFallback)
+			} else {
+				archiveInfo, err = ios.ReadIpa(path, plutilFallback)
+				if archiveInfo != nil {
+					tempDirs = append(tempDirs, archiveInfo.TempDir)
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+
+			dsymPath = archiveInfo.DsymPath
+			log.Info("Using dSYMs from " + path + " (version: " + archiveInfo.PlistData.VersionName + ", bundle version: " + archiveInfo.PlistData.BundleVersion + ")")
+		} else if ios.IsPathAnXcodeProjectOrWorkspace(path) {
 			if xcodeProjPath == "" {
 				xcodeProjPath = path
 			}
@@ -135,10 +170,19 @@ func ProcessDsym(
 			}
 		}
 
+		// If the archive's app has an API key in its Info.plist, use it unless another Info.plist was given
+		if archiveInfo != nil && plistPath == "" && apiKey == "" {
+			apiKey = archiveInfo.PlistData.BugsnagProjectDetails.ApiKey
+			if apiKey != "" {
+				log.AddSecret(apiKey)
+				log.Info("Using API key from the Info.plist in " + path + ": " + apiKey)
+			}
+		}
+
 		// If the Info.plist path is defined and we still don't know the apiKey try to extract them from it
 		if plistPath != "" && apiKey == "" {
 			// Read data from the plist
-			plistData, err = ios.GetPlistData(plistPath)
+			plistData, err = ios.GetPlistData(plistPath, plutilFallback)
 			if err != nil {
 				return err
 			}
@@ -146,16 +190,32 @@ func ProcessDsym(
 			if apiKey == "" {
 				apiKey = plistData.BugsnagProjectDetails.ApiKey
 				if apiKey != "" {
+					log.AddSecret(apiKey)
 					log.Info("Using API key from Info.plist: " + apiKey)
 				}
 			}
 		}
 
+		symbolIds := make(map[string]string)
+		for _, dsym := range dwarfInfo {
+			symbolIds[dsym.Name+" ("+dsym.Arch+")"] = dsym.UUID
+		}
+
+		err = VerifySymbolFiles(symbolIds, verifyAgainst, verifyWarnOnly)
+		if err != nil {
+			return err
+		}
+
+		var tasks []func() error
+
 		for _, dsym := range dwarfInfo {
-			dsymInfo := "(UUID: " + dsym.UUID + ", Name: " + dsym.Name + ", Arch: " + dsym.Arch + ")"
-			log.Info("Uploading dSYM " + dsymInfo)
+			var appVersion, appBundleVersion string
+			if archiveInfo != nil {
+				appVersion = archiveInfo.PlistData.VersionName
+				appBundleVersion = archiveInfo.PlistData.BundleVersion
+			}
 
-			uploadOptions, err = utils.BuildDsymUploadOptions(apiKey, projectRoot)
+			uploadOptions, err := utils.BuildDsymUploadOptions(apiKey, projectRoot, appVersion, appBundleVersion)
 			if err != nil {
 				return err
 			}
@@ -163,20 +223,93 @@ func ProcessDsym(
 			fileFieldData := make(map[string]string)
 			fileFieldData["dsym"] = filepath.Join(dsym.Location, dsym.Name)
 
-			err = server.ProcessFileRequest(endpoint+"/dsym", uploadOptions, fileFieldData, timeout, retries, dsym.UUID, dryRun)
+			log.DiscoverFile(fileFieldData["dsym"], map[string]string{"uuid": dsym.UUID, "arch": dsym.Arch})
 
-			if err != nil {
-				if strings.Contains(err.Error(), "404 Not Found") {
-					err = server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, dsym.UUID, dryRun)
-				}
-			}
+			dsym := dsym
+			tasks = append(tasks, func() error {
+				dsymInfo := "(UUID: " + dsym.UUID + ", Name: " + dsym.Name + ", Arch: " + dsym.Arch + ")"
+				log.Info("Uploading dSYM " + dsymInfo)
 
-			if err != nil {
+				err := server.ProcessFileRequest(endpoint+"/dsym", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, dsym.UUID, dryRun)
+
+				if err != nil {
+					if server.IsNotFound(err) {
+						err = server.ProcessFileRequest(endpoint, uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, dsym.UUID, dryRun)
+					}
+				}
 
 				return err
-			}
+			})
+		}
+
+		err = server.ProcessConcurrently(concurrency, tasks)
+
+		if err != nil {
+
+			return err
 		}
 	}
 
 	return nil
 }
+
+// ProcessAppStoreConnectDsym downloads the dSYMs of a build from App Store Connect and uploads them
+func ProcessAppStoreConnectDsym(
+	apiKey string,
+	keyId string,
+	issuerId string,
+	privateKeyPath string,
+	apiRootUrl string,
+	appId string,
+	buildNumber string,
+	projectRoot string,
+	ignoreMissingDwarf bool,
+	ignoreEmptyDsym bool,
+	verifyAgainst string,
+	verifyWarnOnly bool,
+	plutilFallback bool,
+	endpoint string,
+	timeout int,
+	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
+	dryRun bool,
+) error {
+	required := []struct{ value, flag string }{
+		{keyId, "--app-store-connect-key-id"},
+		{issuerId, "--app-store-connect-issuer-id"},
+		{privateKeyPath, "--app-store-connect-private-key"},
+		{appId, "--app-id"},
+		{buildNumber, "--build-number"},
+		{projectRoot, "--project-root"},
+	}
+
+	for _, option := range required {
+		if option.value == "" {
+			return errors.New(option.flag + " is required when using --from-app-store-connect")
+		}
+	}
+
+	client, err := ios.NewAppStoreConnectClient(keyId, issuerId, privateKeyPath)
+	if err != nil {
+		return err
+	}
+
+	client.ApiRootUrl = apiRootUrl
+	client.HttpClient = &http.Client{Timeout: time.Duration(timeout) * time.Second}
+
+	tempDir, err := os.MkdirTemp("", "bugsnag-cli-app-store-connect-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	log.Info("Fetching dSYMs for build " + buildNumber + " of app " + appId + " from App Store Connect")
+
+	dsymPaths, err := client.DownloadDsyms(appId, buildNumber, tempDir)
+	if err != nil {
+		return err
+	}
+
+	return ProcessDsym(apiKey, "", "", "", plutilFallback, projectRoot, ignoreMissingDwarf, ignoreEmptyDsym, dsymPaths, verifyAgainst, verifyWarnOnly, endpoint, timeout, retries, retryMaxDelay, concurrency, dryRun)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/android"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -40,6 +42,7 @@ func ProcessReactNativeAndroid(
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
 	overwrite bool,
 	dryRun bool,
 ) error {
@@ -132,17 +135,39 @@ func ProcessReactNativeAndroid(
 			}
 		}
 
+		// Hermes builds write the packager and compiler source maps to intermediates, which are composed if the source map
+		// hasn't already been composed with them
+		searchDirs := []string{
+			filepath.Join(buildDirPath, "intermediates", "sourcemaps", "react", variant),
+			filepath.Join(buildDirPath, "generated", "sourcemaps", "react", variant),
+		}
+		if sourceMapPath != "" {
+			searchDirs = append(searchDirs, filepath.Dir(sourceMapPath))
+		}
+		searchDirs = append(searchDirs, filepath.Dir(bundlePath))
+
+		var hermesDir string
+		sourceMapPath, hermesDir, err = resolveHermesSourceMap(bundlePath, sourceMapPath, searchDirs)
+
+		if err != nil {
+			return err
+		}
+
+		defer os.RemoveAll(hermesDir)
+
 		if !utils.FileExists(sourceMapPath) {
 			return fmt.Errorf("unable to find index.android.bundle at " + sourceMapPath)
 		}
 
 		if appManifestPath == "" {
-			appManifestPathExpected := filepath.Join(buildDirPath, "intermediates", "merged_manifests", variant, "AndroidManifest.xml")
-			if utils.FileExists(appManifestPathExpected) {
-				appManifestPath = appManifestPathExpected
+			appManifestPath = android.FindVariantManifest(buildDirPath, variant)
+			if appManifestPath != "" {
 				log.Info("Found app manifest at: " + appManifestPath)
 			} else {
-				log.Info("No app manifest found at: " + appManifestPathExpected)
+				log.Info("No app manifest found for the " + variant + " variant in " + buildDirPath)
+
+				// Source maps aren't uploaded with an application ID, so only the version is used
+				_, versionCode, versionName = android.MergeVariantOutputVersion(buildDirPath, variant, "", versionCode, versionName)
 			}
 		}
 
@@ -161,6 +186,7 @@ func ProcessReactNativeAndroid(
 					}
 				}
 
+				log.AddSecret(apiKey)
 				log.Info("Using " + apiKey + " as API key from AndroidManifest.xml")
 			}
 
@@ -181,11 +207,13 @@ func ProcessReactNativeAndroid(
 			return err
 		}
 
+		log.DiscoverFile(sourceMapPath, map[string]string{"bundle": bundlePath})
+
 		fileFieldData := make(map[string]string)
 		fileFieldData["sourceMap"] = sourceMapPath
 		fileFieldData["bundle"] = bundlePath
 
-		err = server.ProcessFileRequest(endpoint+"/react-native-source-map", uploadOptions, fileFieldData, timeout, retries, sourceMapPath, dryRun)
+		err = server.ProcessFileRequest(endpoint+"/react-native-source-map", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, sourceMapPath, dryRun)
 
 		if err != nil {
 
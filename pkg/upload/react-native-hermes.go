@@ -0,0 +1,58 @@
+package upload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+// resolveHermesSourceMap - Gets the source map to upload for a bundle. When the bundle is Hermes bytecode and the
+// source map hasn't been composed with the compiler's, the packager and compiler source maps found in the given
+// directories are composed into a temporary directory, which is returned so that it can be removed after the upload
+func resolveHermesSourceMap(bundlePath string, sourceMapPath string, searchDirs []string) (string, string, error) {
+	if !utils.IsHermesBytecode(bundlePath) {
+		return sourceMapPath, "", nil
+	}
+
+	log.Info("Found Hermes bytecode bundle at " + bundlePath)
+
+	if utils.FileExists(sourceMapPath) && utils.IsHermesSourceMap(sourceMapPath) {
+		log.Info("Using source map composed by the Hermes compiler at " + sourceMapPath)
+		return sourceMapPath, "", nil
+	}
+
+	packagerMapPath, compilerMapPath := utils.FindHermesSourceMaps(bundlePath, searchDirs)
+
+	if packagerMapPath == "" || compilerMapPath == "" {
+		if utils.FileExists(sourceMapPath) {
+			log.Warn(sourceMapPath + " hasn't been composed with the source map from the Hermes compiler, so stack traces from the bundle may not be symbolicated")
+		}
+
+		return sourceMapPath, "", nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "bugsnag-cli-hermes-*")
+	if err != nil {
+		return "", "", fmt.Errorf("error creating temporary working directory: %w", err)
+	}
+
+	composedMapPath := filepath.Join(tempDir, filepath.Base(bundlePath)+".map")
+
+	log.Info("Composing source map from " + packagerMapPath + " and " + compilerMapPath)
+
+	if err := utils.ComposeHermesSourceMap(packagerMapPath, compilerMapPath, composedMapPath); err != nil {
+		os.RemoveAll(tempDir)
+		return "", "", fmt.Errorf("failed to compose the Hermes source map for %s: %w", bundlePath, err)
+	}
+
+	if utils.FileExists(sourceMapPath) {
+		log.AliasFile(composedMapPath, sourceMapPath)
+	} else {
+		log.AliasFile(composedMapPath, packagerMapPath)
+	}
+
+	return composedMapPath, tempDir, nil
+}
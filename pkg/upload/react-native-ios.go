@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/bugsnag/bugsnag-cli/pkg/ios"
 	"github.com/bugsnag/bugsnag-cli/pkg/log"
@@ -13,17 +14,18 @@ import (
 )
 
 type ReactNativeIos struct {
-	VersionName   string      `help:"The version of the application."`
-	BundleVersion string      `help:"Bundle version for the application. (iOS only)"`
-	Scheme        string      `help:"The name of the scheme to use when building the application."`
-	SourceMap     string      `help:"Path to the source map file" type:"path"`
-	Bundle        string      `help:"Path to the bundle file" type:"path"`
-	Plist         string      `help:"Path to the Info.plist file" type:"path"`
-	XcodeProject  string      `help:"Path to the .xcworkspace file" type:"path"`
-	CodeBundleID  string      `help:"A unique identifier to identify a code bundle release when using tools like CodePush"`
-	Dev           bool        `help:"Indicates whether the application is a debug or release build"`
-	ProjectRoot   string      `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
-	Path          utils.Paths `arg:"" name:"path" help:"Path to directory or file to upload" type:"path" default:"."`
+	VersionName    string      `help:"The version of the application."`
+	BundleVersion  string      `help:"Bundle version for the application. (iOS only)"`
+	Scheme         string      `help:"The name of the scheme to use when building the application."`
+	SourceMap      string      `help:"Path to the source map file" type:"path"`
+	Bundle         string      `help:"Path to the bundle file" type:"path"`
+	Plist          string      `help:"Path to the Info.plist file" type:"path"`
+	XcodeProject   string      `help:"Path to the .xcworkspace file" type:"path"`
+	CodeBundleID   string      `help:"A unique identifier to identify a code bundle release when using tools like CodePush"`
+	Dev            bool        `help:"Indicates whether the application is a debug or release build"`
+	ProjectRoot    string      `help:"path to remove from the beginning of the filenames in the mapping file" type:"path"`
+	PlutilFallback bool        `help:"Use plutil to read Info.plist files that can't be read natively (macOS only)"`
+	Path           utils.Paths `arg:"" name:"path" help:"Path to directory or file to upload" type:"path" default:"."`
 }
 
 func ProcessReactNativeIos(
@@ -34,6 +36,7 @@ func ProcessReactNativeIos(
 	sourceMapPath string,
 	bundlePath string,
 	plistPath string,
+	plutilFallback bool,
 	xcodeProjPath string,
 	codeBundleId string,
 	dev bool,
@@ -42,6 +45,7 @@ func ProcessReactNativeIos(
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
 	overwrite bool,
 	dryRun bool,
 ) error {
@@ -184,6 +188,23 @@ func ProcessReactNativeIos(
 			}
 		}
 
+		// Hermes builds are uploaded with the source map composed from the packager and compiler source maps
+		searchDirs := []string{filepath.Dir(bundlePath)}
+		if sourceMapPath != "" {
+			searchDirs = append([]string{filepath.Dir(sourceMapPath)}, searchDirs...)
+		}
+		if buildSettings != nil {
+			searchDirs = append(searchDirs, buildSettings.ConfigurationBuildDir)
+		}
+
+		var hermesDir string
+		sourceMapPath, hermesDir, err = resolveHermesSourceMap(bundlePath, sourceMapPath, searchDirs)
+		if err != nil {
+			return err
+		}
+
+		defer os.RemoveAll(hermesDir)
+
 		// Check that we now have a source map path
 		if sourceMapPath == "" {
 			return errors.New("Could not find a source map, please specify the path by using --source-map or SOURCEMAP_FILE environment variable")
@@ -191,7 +212,7 @@ func ProcessReactNativeIos(
 
 		if plistPath != "" && (apiKey == "" || versionName == "" || bundleVersion == "") {
 			// Read data from the plist
-			plistData, err := ios.GetPlistData(plistPath)
+			plistData, err := ios.GetPlistData(plistPath, plutilFallback)
 			if err != nil {
 				return err
 			}
@@ -210,6 +231,7 @@ func ProcessReactNativeIos(
 
 			if apiKey == "" {
 				apiKey = plistData.BugsnagProjectDetails.ApiKey
+				log.AddSecret(apiKey)
 				log.Info("Using API key from Info.plist: " + apiKey)
 			}
 
@@ -223,11 +245,13 @@ func ProcessReactNativeIos(
 		return err
 	}
 
+	log.DiscoverFile(sourceMapPath, map[string]string{"bundle": bundlePath})
+
 	fileFieldData := make(map[string]string)
 	fileFieldData["sourceMap"] = sourceMapPath
 	fileFieldData["bundle"] = bundlePath
 
-	err = server.ProcessFileRequest(endpoint+"/react-native-source-map", uploadOptions, fileFieldData, timeout, retries, sourceMapPath, dryRun)
+	err = server.ProcessFileRequest(endpoint+"/react-native-source-map", uploadOptions, fileFieldData, timeout, retries, retryMaxDelay, sourceMapPath, dryRun)
 
 	if err != nil {
 
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type UnityAndroid struct {
@@ -34,6 +35,8 @@ func ProcessUnityAndroid(
 	endpoint string,
 	timeout int,
 	retries int,
+	retryMaxDelay time.Duration,
+	concurrency int,
 	overwrite bool,
 	dryRun bool,
 ) error {
@@ -96,6 +99,8 @@ func ProcessUnityAndroid(
 			endpoint,
 			retries,
 			timeout,
+			retryMaxDelay,
+			concurrency,
 			overwrite,
 			dryRun,
 		)
@@ -132,7 +137,10 @@ func ProcessUnityAndroid(
 			return err
 		}
 		for _, file := range fileList {
+			log.DiscoverFile(file, map[string]string{"arch": arch})
+
 			if filepath.Base(file) == "libil2cpp.sym.so" && utils.ContainsString(fileList, "libil2cpp.dbg.so") {
+				log.RecordFile(log.FileEvent{File: file, Status: log.StatusSkipped})
 				continue
 			}
 			symbolFileList = append(symbolFileList, file)
@@ -150,6 +158,8 @@ func ProcessUnityAndroid(
 		endpoint,
 		timeout,
 		retries,
+		retryMaxDelay,
+		concurrency,
 		dryRun,
 	)
 
@@ -0,0 +1,192 @@
+package upload
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+)
+
+// VerifySymbolFiles - Checks that the UUID or build ID of each symbol file (keyed by a description of the file)
+// is found in the shipped binary at verifyAgainstPath. Symbol files without a UUID or build ID don't match. A mismatch
+// fails the upload unless warnOnly is set. Does nothing if no binary was given.
+func VerifySymbolFiles(symbolIds map[string]string, verifyAgainstPath string, warnOnly bool) error {
+	if verifyAgainstPath == "" || len(symbolIds) == 0 {
+		return nil
+	}
+
+	binaryIds, err := GetBinaryIdentifiers(verifyAgainstPath)
+	if err != nil {
+		return fmt.Errorf("unable to read the UUIDs or build IDs of %s: %w", verifyAgainstPath, err)
+	}
+
+	if len(binaryIds) == 0 {
+		return fmt.Errorf("no UUIDs or build IDs found in %s", verifyAgainstPath)
+	}
+
+	var files []string
+	for file := range symbolIds {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var mismatches []string
+	for _, file := range files {
+		if symbolIds[file] == "" {
+			mismatches = append(mismatches, file+" (no UUID or build ID)")
+		} else if !binaryIds[normaliseIdentifier(symbolIds[file])] {
+			mismatches = append(mismatches, file+" ("+symbolIds[file]+")")
+		}
+	}
+
+	if len(mismatches) == 0 {
+		log.Info(fmt.Sprintf("Verified that %d symbol file(s) match %s", len(files), verifyAgainstPath))
+		return nil
+	}
+
+	message := fmt.Sprintf("%d of %d symbol file(s) don't match %s: %s", len(mismatches), len(files), verifyAgainstPath, strings.Join(mismatches, ", "))
+
+	if warnOnly {
+		log.Warn(message)
+		return nil
+	}
+
+	return fmt.Errorf("%s. Use --verify-warn-only to upload them anyway", message)
+}
+
+// GetBinaryIdentifiers - Reads the UUIDs and GNU build IDs of the Mach-O and ELF files in a binary, a directory
+// (such as a .app bundle) or a zip based package (.ipa, .apk or .aab)
+func GetBinaryIdentifiers(path string) (map[string]bool, error) {
+	identifiers := make(map[string]bool)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			return addFileIdentifiers(file, identifiers)
+		})
+
+		return identifiers, err
+	}
+
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return identifiers, addFileIdentifiers(path, identifiers)
+	}
+	defer zipReader.Close()
+
+	for _, file := range zipReader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if err := addZipFileIdentifiers(file, identifiers); err != nil {
+			return nil, err
+		}
+	}
+
+	return identifiers, nil
+}
+
+// addFileIdentifiers - Adds the identifiers of a file to the set, if it is a Mach-O or ELF file
+func addFileIdentifiers(path string, identifiers map[string]bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(file, magic); err != nil || !isExecutableMagic(magic) {
+		return nil
+	}
+
+	addIdentifiers(file, identifiers)
+
+	return nil
+}
+
+// addZipFileIdentifiers - Adds the identifiers of a file within a zip to the set, if it is a Mach-O or ELF file
+func addZipFileIdentifiers(file *zip.File, identifiers map[string]bool) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(reader, magic)
+	reader.Close()
+
+	if err != nil || !isExecutableMagic(magic) {
+		return nil
+	}
+
+	reader, err = file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	addIdentifiers(bytes.NewReader(data), identifiers)
+
+	return nil
+}
+
+// addIdentifiers - Adds the GNU build ID of ELF data, or the UUIDs of each slice of Mach-O data, to the set
+func addIdentifiers(reader io.ReaderAt, identifiers map[string]bool) {
+	if elfFile, err := elf.NewFile(reader); err == nil {
+		if buildId, err := android.GetElfBuildId(elfFile); err == nil {
+			identifiers[normaliseIdentifier(buildId)] = true
+		}
+		return
+	}
+
+	dwarfInfo, err := ios.GetMachOUuidsFromReader(reader)
+	if err != nil {
+		return
+	}
+
+	for _, dwarf := range dwarfInfo {
+		identifiers[normaliseIdentifier(dwarf.UUID)] = true
+	}
+}
+
+// isExecutableMagic - Checks for the magic number of an ELF file or a thin or fat Mach-O file
+func isExecutableMagic(magic []byte) bool {
+	if bytes.Equal(magic, []byte(elf.ELFMAG)) {
+		return true
+	}
+
+	switch string(magic) {
+	case "\xfe\xed\xfa\xce", "\xfe\xed\xfa\xcf", "\xce\xfa\xed\xfe", "\xcf\xfa\xed\xfe", "\xca\xfe\xba\xbe":
+		return true
+	}
+
+	return false
+}
+
+// normaliseIdentifier - Formats a UUID or build ID for comparison, as UUIDs are written in upper case with dashes
+func normaliseIdentifier(identifier string) string {
+	return strings.ToLower(strings.ReplaceAll(identifier, "-", ""))
+}
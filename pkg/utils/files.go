@@ -12,7 +12,6 @@ import (
 const (
 	PLUTIL     = "plutil"
 	XCODEBUILD = "xcodebuild"
-	DWARFDUMP  = "dwarfdump"
 )
 
 // FilePathWalkDir - finds files within a given directory
@@ -5,35 +5,43 @@ import (
 	"compress/gzip"
 	"io"
 	"os"
+	"path/filepath"
 )
 
 func GzipCompress(file string) (string, error) {
+	return GzipCompressToDir(file, filepath.Dir(file))
+}
+
+// GzipCompressToDir - Compresses a file to <name>.gz in the given directory, so that nothing is written next to it
+func GzipCompressToDir(file string, outputDir string) (string, error) {
 	fileData, err := os.Open(file)
 
 	if err != nil {
 		return "", err
 	}
+	defer fileData.Close()
 
 	read := bufio.NewReader(fileData)
 
-	if err != nil {
-		return "", err
-	}
-
-	newFile := file + ".gz"
+	newFile := filepath.Join(outputDir, filepath.Base(file)+".gz")
 
 	gzipFile, err := os.Create(newFile)
 
 	if err != nil {
 		return "", err
 	}
+	defer gzipFile.Close()
 
 	w := gzip.NewWriter(gzipFile)
-	defer w.Close()
 	_, err = io.Copy(w, read)
 	if err != nil {
 		return "", err
 	}
 
+	err = w.Close()
+	if err != nil {
+		return "", err
+	}
+
 	return newFile, nil
 }
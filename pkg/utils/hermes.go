@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// hermesBytecodeMagic - The magic number at the start of a bundle compiled to Hermes bytecode
+var hermesBytecodeMagic = []byte{0xc6, 0x1f, 0xbc, 0x03, 0xc1, 0x03, 0x19, 0x1f}
+
+// IsHermesBytecode - Checks whether a React Native bundle has been compiled to Hermes bytecode
+func IsHermesBytecode(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(hermesBytecodeMagic))
+	if _, err := file.Read(magic); err != nil {
+		return false
+	}
+
+	return bytes.Equal(magic, hermesBytecodeMagic)
+}
+
+// IsHermesSourceMap - Checks whether a source map has been composed with the source map from the Hermes compiler,
+// which adds the function offsets used to symbolicate bytecode
+func IsHermesSourceMap(path string) bool {
+	sourceMap, err := ReadSourceMap(path)
+	if err != nil {
+		return false
+	}
+
+	return len(sourceMap.HermesFunctionOffsets) > 0 && string(sourceMap.HermesFunctionOffsets) != "null"
+}
+
+// FindHermesSourceMaps - Finds the source map written by the packager for a bundle and the one written by the Hermes
+// compiler for its bytecode, named <bundle>.packager.map and <bundle>.compiler.map, in the first of the given
+// directories that has each of them
+func FindHermesSourceMaps(bundlePath string, dirs []string) (packagerMapPath string, compilerMapPath string) {
+	bundleName := filepath.Base(bundlePath)
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+
+		if packagerMapPath == "" && FileExists(filepath.Join(dir, bundleName+".packager.map")) {
+			packagerMapPath = filepath.Join(dir, bundleName+".packager.map")
+		}
+
+		if compilerMapPath == "" && FileExists(filepath.Join(dir, bundleName+".compiler.map")) {
+			compilerMapPath = filepath.Join(dir, bundleName+".compiler.map")
+		}
+	}
+
+	return packagerMapPath, compilerMapPath
+}
+
+// ComposeHermesSourceMap - Composes the packager and Hermes compiler source maps of a bundle into the source map of
+// its bytecode, writing it to the output path
+func ComposeHermesSourceMap(packagerMapPath string, compilerMapPath string, outputPath string) error {
+	packagerMap, err := ReadSourceMap(packagerMapPath)
+	if err != nil {
+		return err
+	}
+
+	compilerMap, err := ReadSourceMap(compilerMapPath)
+	if err != nil {
+		return err
+	}
+
+	composedMap, err := ComposeSourceMaps(packagerMap, compilerMap)
+	if err != nil {
+		return err
+	}
+
+	return WriteSourceMap(outputPath, composedMap)
+}
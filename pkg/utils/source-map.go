@@ -0,0 +1,351 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// base64Chars - The characters used to encode the base64 VLQ values of source map mappings
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// SourceMap - A version 3 JavaScript source map, along with the extension fields written by Metro and Hermes
+type SourceMap struct {
+	Version               int               `json:"version"`
+	File                  string            `json:"file,omitempty"`
+	SourceRoot            string            `json:"sourceRoot,omitempty"`
+	Sources               []string          `json:"sources"`
+	SourcesContent        []*string         `json:"sourcesContent,omitempty"`
+	Names                 []string          `json:"names"`
+	Mappings              string            `json:"mappings"`
+	Sections              []json.RawMessage `json:"sections,omitempty"`
+	FacebookSources       []json.RawMessage `json:"x_facebook_sources,omitempty"`
+	HermesFunctionOffsets json.RawMessage   `json:"x_hermes_function_offsets,omitempty"`
+	GoogleIgnoreList      []int             `json:"x_google_ignoreList,omitempty"`
+}
+
+// SourceMapSegment - A decoded mapping from a position in the generated file to a position in an original source.
+// Lines and columns are zero-based, and Source and Name are -1 when the segment doesn't have them
+type SourceMapSegment struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	Source          int
+	OriginalLine    int
+	OriginalColumn  int
+	Name            int
+}
+
+// ReadSourceMap - Reads a JavaScript source map
+func ReadSourceMap(path string) (*SourceMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceMap SourceMap
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		return nil, fmt.Errorf("unable to parse source map %s: %w", path, err)
+	}
+
+	return &sourceMap, nil
+}
+
+// WriteSourceMap - Writes a JavaScript source map
+func WriteSourceMap(path string, sourceMap *SourceMap) error {
+	data, err := json.Marshal(sourceMap)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// DecodeMappings - Decodes the base64 VLQ mappings of a source map into segments, rejecting negative positions
+func DecodeMappings(mappings string) ([]SourceMapSegment, error) {
+	var segments []SourceMapSegment
+	var source, originalLine, originalColumn, name int
+
+	for line, lineMappings := range strings.Split(mappings, ";") {
+		generatedColumn := 0
+
+		for _, encoded := range strings.Split(lineMappings, ",") {
+			if encoded == "" {
+				continue
+			}
+
+			fields, err := decodeVlq(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mapping %q on line %d: %w", encoded, line+1, err)
+			}
+
+			if len(fields) != 1 && len(fields) != 4 && len(fields) != 5 {
+				return nil, fmt.Errorf("invalid mapping %q on line %d: expected 1, 4 or 5 fields", encoded, line+1)
+			}
+
+			generatedColumn += fields[0]
+			segment := SourceMapSegment{GeneratedLine: line, GeneratedColumn: generatedColumn, Source: -1, Name: -1}
+
+			if len(fields) >= 4 {
+				source += fields[1]
+				originalLine += fields[2]
+				originalColumn += fields[3]
+				segment.Source = source
+				segment.OriginalLine = originalLine
+				segment.OriginalColumn = originalColumn
+			}
+
+			if len(fields) == 5 {
+				name += fields[4]
+				segment.Name = name
+			}
+
+			// The fields are relative to the previous segment, so a malformed map can sum to a negative position
+			if generatedColumn < 0 || (len(fields) >= 4 && (source < 0 || originalLine < 0 || originalColumn < 0)) || (len(fields) == 5 && name < 0) {
+				return nil, fmt.Errorf("invalid mapping %q on line %d: negative column, source, line or name", encoded, line+1)
+			}
+
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments, nil
+}
+
+// EncodeMappings - Encodes segments, ordered by their generated position, into the base64 VLQ mappings of a source map
+func EncodeMappings(segments []SourceMapSegment) string {
+	var builder strings.Builder
+	var source, originalLine, originalColumn, name int
+	line, generatedColumn := 0, 0
+
+	for i, segment := range segments {
+		if segment.GeneratedLine > line {
+			builder.WriteString(strings.Repeat(";", segment.GeneratedLine-line))
+			line = segment.GeneratedLine
+			generatedColumn = 0
+		} else if i > 0 {
+			builder.WriteByte(',')
+		}
+
+		builder.WriteString(encodeVlq(segment.GeneratedColumn - generatedColumn))
+		generatedColumn = segment.GeneratedColumn
+
+		if segment.Source < 0 {
+			continue
+		}
+
+		builder.WriteString(encodeVlq(segment.Source - source))
+		builder.WriteString(encodeVlq(segment.OriginalLine - originalLine))
+		builder.WriteString(encodeVlq(segment.OriginalColumn - originalColumn))
+		source, originalLine, originalColumn = segment.Source, segment.OriginalLine, segment.OriginalColumn
+
+		if segment.Name >= 0 {
+			builder.WriteString(encodeVlq(segment.Name - name))
+			name = segment.Name
+		}
+	}
+
+	return builder.String()
+}
+
+// ComposeSourceMaps - Composes the source map of a bundle with the source map of the file it was compiled into, so
+// that positions in the compiled file map straight to the original sources, in the same way as Metro's
+// compose-source-maps.js. Mappings to a source or name that the bundle's source map doesn't have are an error
+func ComposeSourceMaps(bundleMap *SourceMap, compiledMap *SourceMap) (*SourceMap, error) {
+	for _, sourceMap := range []*SourceMap{bundleMap, compiledMap} {
+		if len(sourceMap.Sections) > 0 {
+			return nil, fmt.Errorf("composing index source maps with sections isn't supported")
+		}
+
+		if sourceMap.Version != 3 {
+			return nil, fmt.Errorf("only version 3 source maps can be composed, found version %d", sourceMap.Version)
+		}
+	}
+
+	bundleSegments, err := DecodeMappings(bundleMap.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	compiledSegments, err := DecodeMappings(compiledMap.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the bundle's segments by line, which are already ordered by column, to look up positions in the bundle
+	var bundleLines [][]SourceMapSegment
+	for _, segment := range bundleSegments {
+		for len(bundleLines) <= segment.GeneratedLine {
+			bundleLines = append(bundleLines, nil)
+		}
+		bundleLines[segment.GeneratedLine] = append(bundleLines[segment.GeneratedLine], segment)
+	}
+
+	composed := &SourceMap{Version: 3, Sources: []string{}, Names: []string{}, SourceRoot: bundleMap.SourceRoot}
+	sourceIndexes := make(map[int]int)
+	nameIndexes := make(map[int]int)
+	var bundleSources []int
+	var segments []SourceMapSegment
+
+	for _, segment := range compiledSegments {
+		composedSegment := SourceMapSegment{
+			GeneratedLine:   segment.GeneratedLine,
+			GeneratedColumn: segment.GeneratedColumn,
+			Source:          -1,
+			Name:            -1,
+		}
+
+		if original, found := findOriginalSegment(bundleLines, segment); found {
+			if original.Source >= len(bundleMap.Sources) {
+				return nil, fmt.Errorf("mapping on line %d refers to source %d, but the source map has %d sources", original.GeneratedLine+1, original.Source, len(bundleMap.Sources))
+			}
+
+			if original.Name >= len(bundleMap.Names) {
+				return nil, fmt.Errorf("mapping on line %d refers to name %d, but the source map has %d names", original.GeneratedLine+1, original.Name, len(bundleMap.Names))
+			}
+
+			index, ok := sourceIndexes[original.Source]
+			if !ok {
+				index = len(composed.Sources)
+				sourceIndexes[original.Source] = index
+				bundleSources = append(bundleSources, original.Source)
+				composed.Sources = append(composed.Sources, bundleMap.Sources[original.Source])
+			}
+
+			composedSegment.Source = index
+			composedSegment.OriginalLine = original.OriginalLine
+			composedSegment.OriginalColumn = original.OriginalColumn
+
+			if original.Name >= 0 {
+				index, ok := nameIndexes[original.Name]
+				if !ok {
+					index = len(composed.Names)
+					nameIndexes[original.Name] = index
+					composed.Names = append(composed.Names, bundleMap.Names[original.Name])
+				}
+
+				composedSegment.Name = index
+			}
+		}
+
+		segments = append(segments, composedSegment)
+	}
+
+	composed.Mappings = EncodeMappings(segments)
+
+	// Carry over the content and metadata of each source that is still mapped to, along with the function offsets
+	// that Hermes uses to symbolicate bytecode
+	hasContent := false
+	for _, source := range bundleSources {
+		var content *string
+		if source < len(bundleMap.SourcesContent) {
+			content = bundleMap.SourcesContent[source]
+		}
+
+		hasContent = hasContent || content != nil
+		composed.SourcesContent = append(composed.SourcesContent, content)
+
+		if len(bundleMap.FacebookSources) > 0 {
+			var metadata json.RawMessage
+			if source < len(bundleMap.FacebookSources) {
+				metadata = bundleMap.FacebookSources[source]
+			}
+			composed.FacebookSources = append(composed.FacebookSources, metadata)
+		}
+	}
+
+	if !hasContent {
+		composed.SourcesContent = nil
+	}
+
+	for _, source := range bundleMap.GoogleIgnoreList {
+		if index, ok := sourceIndexes[source]; ok {
+			composed.GoogleIgnoreList = append(composed.GoogleIgnoreList, index)
+		}
+	}
+	sort.Ints(composed.GoogleIgnoreList)
+
+	composed.HermesFunctionOffsets = compiledMap.HermesFunctionOffsets
+
+	return composed, nil
+}
+
+// findOriginalSegment - Finds the segment of the bundle that a segment of the compiled file maps to, which is the
+// closest one at or before its position on the same line
+func findOriginalSegment(bundleLines [][]SourceMapSegment, segment SourceMapSegment) (SourceMapSegment, bool) {
+	if segment.Source < 0 || segment.OriginalLine < 0 || segment.OriginalLine >= len(bundleLines) {
+		return SourceMapSegment{}, false
+	}
+
+	line := bundleLines[segment.OriginalLine]
+	index := sort.Search(len(line), func(i int) bool {
+		return line[i].GeneratedColumn > segment.OriginalColumn
+	})
+
+	if index == 0 || line[index-1].Source < 0 {
+		return SourceMapSegment{}, false
+	}
+
+	return line[index-1], true
+}
+
+// decodeVlq - Decodes the base64 VLQ values of a mapping segment
+func decodeVlq(encoded string) ([]int, error) {
+	var values []int
+	value, shift := 0, 0
+
+	for i := 0; i < len(encoded); i++ {
+		digit := strings.IndexByte(base64Chars, encoded[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base64 character %q", encoded[i])
+		}
+
+		value += (digit & 31) << shift
+
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+
+		value, shift = 0, 0
+	}
+
+	if shift != 0 {
+		return nil, fmt.Errorf("unterminated value")
+	}
+
+	return values, nil
+}
+
+// encodeVlq - Encodes a value as base64 VLQ
+func encodeVlq(value int) string {
+	var builder strings.Builder
+
+	if value < 0 {
+		value = (-value << 1) | 1
+	} else {
+		value <<= 1
+	}
+
+	for {
+		digit := value & 31
+		value >>= 5
+
+		if value > 0 {
+			digit |= 32
+		}
+
+		builder.WriteByte(base64Chars[digit])
+
+		if value == 0 {
+			return builder.String()
+		}
+	}
+}
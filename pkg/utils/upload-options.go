@@ -42,13 +42,13 @@ func BuildDartUploadOptions(apiKey string, uuid string, platform string, overwri
 }
 
 // BuildAndroidProguardUploadOptions - Builds the upload options for processing Proguard files
-func BuildAndroidProguardUploadOptions(apiKey string, applicationId string, versionName string, versionCode string, buildUuid string, overwrite bool) (map[string]string, error) {
+func BuildAndroidProguardUploadOptions(apiKey string, applicationId string, versionName string, versionCode string, buildUuid string, mapId string, overwrite bool) (map[string]string, error) {
 	uploadOptions := make(map[string]string)
 
 	if apiKey != "" {
 		uploadOptions["apiKey"] = apiKey
 	} else {
-		return nil, fmt.Errorf("missing api key, please specify using `--api-key`")
+		return nil, fmt.Errorf("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable")
 	}
 
 	if applicationId != "" {
@@ -67,6 +67,10 @@ func BuildAndroidProguardUploadOptions(apiKey string, applicationId string, vers
 		uploadOptions["buildUUID"] = buildUuid
 	}
 
+	if mapId != "" {
+		uploadOptions["mapId"] = mapId
+	}
+
 	if overwrite {
 		uploadOptions["overwrite"] = "true"
 	}
@@ -78,17 +82,25 @@ func BuildAndroidProguardUploadOptions(apiKey string, applicationId string, vers
 	return uploadOptions, nil
 }
 
-func BuildDsymUploadOptions(apiKey string, projectRoot string) (map[string]string, error) {
+func BuildDsymUploadOptions(apiKey string, projectRoot string, appVersion string, appBundleVersion string) (map[string]string, error) {
 	uploadOptions := make(map[string]string)
 
 	if apiKey != "" {
 		uploadOptions["apiKey"] = apiKey
 	} else {
-		return nil, fmt.Errorf("missing api key, please specify using `--api-key`")
+		return nil, fmt.Errorf("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable")
 	}
 
 	uploadOptions["projectRoot"] = projectRoot
 
+	if appVersion != "" {
+		uploadOptions["appVersion"] = appVersion
+	}
+
+	if appBundleVersion != "" {
+		uploadOptions["appBundleVersion"] = appBundleVersion
+	}
+
 	return uploadOptions, nil
 }
 
@@ -110,7 +122,7 @@ func BuildReactNativeUploadOptions(apiKey string, appVersion string, versionCode
 	if apiKey != "" {
 		uploadOptions["apiKey"] = apiKey
 	} else {
-		return nil, fmt.Errorf("missing api key, please specify using `--api-key`")
+		return nil, fmt.Errorf("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable")
 	}
 
 	uploadOptions["appVersion"] = appVersion
@@ -142,13 +154,13 @@ func BuildReactNativeUploadOptions(apiKey string, appVersion string, versionCode
 }
 
 // BuildAndroidNDKUploadOptions - Builds the upload options for processing NDK files
-func BuildAndroidNDKUploadOptions(apiKey string, applicationId string, versionName string, versionCode string, projectRoot string, sharedObjectName string, overwrite bool) (map[string]string, error) {
+func BuildAndroidNDKUploadOptions(apiKey string, applicationId string, versionName string, versionCode string, projectRoot string, sharedObjectName string, buildId string, arch string, overwrite bool) (map[string]string, error) {
 	uploadOptions := make(map[string]string)
 
 	if apiKey != "" {
 		uploadOptions["apiKey"] = apiKey
 	} else {
-		return nil, fmt.Errorf("missing api key, please specify using `--api-key`")
+		return nil, fmt.Errorf("missing api key, please specify using `--api-key` or the BUGSNAG_API_KEY environment variable")
 	}
 
 	if applicationId != "" {
@@ -171,6 +183,14 @@ func BuildAndroidNDKUploadOptions(apiKey string, applicationId string, versionNa
 		uploadOptions["sharedObjectName"] = sharedObjectName
 	}
 
+	if buildId != "" {
+		uploadOptions["buildId"] = buildId
+	}
+
+	if arch != "" {
+		uploadOptions["arch"] = arch
+	}
+
 	if overwrite {
 		uploadOptions["overwrite"] = "true"
 	}
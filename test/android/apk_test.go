@@ -0,0 +1,76 @@
+package android_testing
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readApkManifest - Reads the binary manifest from the test APK
+func readApkManifest(t *testing.T) []byte {
+	apk, err := zip.OpenReader("../testdata/android/apk/app-release.apk")
+	require.NoError(t, err)
+	defer apk.Close()
+
+	manifest, err := apk.Open("AndroidManifest.xml")
+	require.NoError(t, err)
+	defer manifest.Close()
+
+	content, err := io.ReadAll(manifest)
+	require.NoError(t, err)
+
+	return content
+}
+
+func TestReadApkManifest(t *testing.T) {
+	t.Log("Testing reading a binary XML manifest from an APK")
+	content := readApkManifest(t)
+
+	manifestData, err := android.ReadApkManifest(content)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"applicationId": "com.example.apkapp",
+		"versionCode":   "42",
+		"versionName":   "1.2.3",
+		"apiKey":        "1234567890abcdef1234567890abcdef",
+	}, manifestData)
+
+	t.Log("Testing that truncated manifests are rejected")
+	_, err = android.ReadApkManifest(content[:len(content)/2])
+	assert.Error(t, err)
+
+	t.Log("Testing that plain XML manifests are rejected")
+	_, err = android.ReadApkManifest([]byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example" />`))
+	assert.Error(t, err)
+}
+
+func TestMergeUploadOptionsFromApkManifest(t *testing.T) {
+	apkDir, err := utils.ExtractFile("../testdata/android/apk/app-release.apk", "apk")
+	require.NoError(t, err)
+	defer os.RemoveAll(apkDir)
+
+	t.Log("Testing that the build UUID is taken from the dex signatures")
+	options, err := android.MergeUploadOptionsFromApkManifest(apkDir, "", "", "", false, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"apiKey":        "1234567890abcdef1234567890abcdef",
+		"applicationId": "com.example.apkapp",
+		"buildUuid":     "f3112c3dbdd73ae5dee677e407af196f101e97f5",
+		"versionCode":   "42",
+		"versionName":   "1.2.3",
+	}, options)
+
+	t.Log("Testing that given options take precedence over the manifest")
+	options, err = android.MergeUploadOptionsFromApkManifest(apkDir, "", "com.example.override", "", true, "43", "")
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.override", options["applicationId"])
+	assert.Equal(t, "43", options["versionCode"])
+	assert.Equal(t, "1.2.3", options["versionName"])
+	assert.Equal(t, "", options["buildUuid"])
+}
@@ -0,0 +1,52 @@
+package android_testing
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAxmlManifest(t *testing.T) {
+	t.Log("Testing reading a binary XML manifest with a UTF-16 string pool")
+	manifestData, err := android.ParseAxmlManifest(readApkManifest(t))
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.apkapp", manifestData.ApplicationId)
+	assert.Equal(t, "42", manifestData.VersionCode)
+	assert.Equal(t, "1.2.3", manifestData.VersionName)
+	assert.Equal(t, []string{"com.bugsnag.android.API_KEY", "com.bugsnag.android.RELEASE_STAGE"}, manifestData.Application.MetaData.Name)
+	assert.Equal(t, []string{"1234567890abcdef1234567890abcdef", "production"}, manifestData.Application.MetaData.Value)
+
+	t.Log("Testing reading a binary XML manifest with a UTF-8 string pool")
+	content, err := os.ReadFile("../testdata/android/AndroidManifest-binary.xml")
+	require.NoError(t, err)
+	manifestData, err = android.ParseAxmlManifest(content)
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.apkapp", manifestData.ApplicationId)
+	require.Len(t, manifestData.Application.MetaData.Value, 3)
+	assert.Equal(t, "com.example.LONG_VALUE", manifestData.Application.MetaData.Name[2])
+	assert.Equal(t, strings.Repeat("é", 300), manifestData.Application.MetaData.Value[2])
+}
+
+func TestReadBinaryManifestFiles(t *testing.T) {
+	t.Log("Testing that binary manifests are read when building Android info")
+	androidData, err := android.BuildAndroidInfo("../testdata/android/AndroidManifest-binary.xml")
+	require.NoError(t, err)
+	assert.Equal(t, "42", androidData.VersionCode)
+	assert.Equal(t, "1.2.3", androidData.VersionName)
+	assert.Equal(t, "com.bugsnag.android.API_KEY", androidData.Application.MetaData.Name[0])
+
+	t.Log("Testing that binary manifests are read when given with --app-manifest")
+	manifest, err := android.ParseAndroidManifestXML("../testdata/android/AndroidManifest-binary.xml")
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.apkapp", manifest.ApplicationId)
+	assert.Equal(t, "1234567890abcdef1234567890abcdef", manifest.Application.MetaData.Value[0])
+
+	t.Log("Testing that plain XML manifests are still read")
+	manifest, err = android.ParseAndroidManifestXML("../testdata/android/AndroidManifest.xml")
+	require.NoError(t, err)
+	assert.NotEmpty(t, manifest.ApplicationId)
+}
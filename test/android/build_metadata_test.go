@@ -0,0 +1,84 @@
+package android_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const agp8BuildDir = "../testdata/android/agp8/app/build"
+
+func TestReadOutputMetadata(t *testing.T) {
+	t.Log("Testing reading the output metadata of an APK")
+	metadata, err := android.ReadOutputMetadata(filepath.Join(agp8BuildDir, "outputs", "apk", "release", "output-metadata.json"))
+	require.NoError(t, err)
+	assert.Equal(t, android.ArtifactApk, metadata.ArtifactType.Type)
+	assert.Equal(t, "com.example.agp8", metadata.ApplicationId)
+	assert.Equal(t, "release", metadata.VariantName)
+	require.Len(t, metadata.Elements, 1)
+	assert.Equal(t, 7, metadata.Elements[0].VersionCode)
+	assert.Equal(t, "app-release.apk", metadata.Elements[0].OutputFile)
+}
+
+func TestFindVariantManifestFromOutputMetadata(t *testing.T) {
+	t.Log("Testing finding a manifest in a directory described by its output metadata")
+	manifestPath := android.FindVariantManifest(agp8BuildDir, "release")
+	assert.Equal(t, filepath.Join(agp8BuildDir, "intermediates", "future_manifests", "release", "AndroidManifest.xml"), manifestPath)
+
+	assert.Equal(t, "", android.FindVariantManifest(agp8BuildDir, "debug"))
+}
+
+func TestFindVariantManifestInKnownLayouts(t *testing.T) {
+	buildDir := t.TempDir()
+
+	t.Log("Testing finding a manifest written to a directory named after the task")
+	manifestDir := filepath.Join(buildDir, "intermediates", "merged_manifest", "release", "processReleaseMainManifest")
+	require.NoError(t, os.MkdirAll(manifestDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "AndroidManifest.xml"), []byte("<manifest/>"), 0644))
+	assert.Equal(t, filepath.Join(manifestDir, "AndroidManifest.xml"), android.FindVariantManifest(buildDir, "release"))
+
+	t.Log("Testing that merged_manifests is preferred over merged_manifest")
+	manifestDir = filepath.Join(buildDir, "intermediates", "merged_manifests", "release")
+	require.NoError(t, os.MkdirAll(manifestDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(manifestDir, "AndroidManifest.xml"), []byte("<manifest/>"), 0644))
+	assert.Equal(t, filepath.Join(manifestDir, "AndroidManifest.xml"), android.FindVariantManifest(buildDir, "release"))
+}
+
+func TestGetVariantOutputVersion(t *testing.T) {
+	t.Log("Testing reading the version of a variant from its APK output metadata")
+	applicationId, versionCode, versionName := android.GetVariantOutputVersion(agp8BuildDir, "release")
+	assert.Equal(t, "com.example.agp8", applicationId)
+	assert.Equal(t, "7", versionCode)
+	assert.Equal(t, "2.0.0", versionName)
+
+	t.Log("Testing that given options aren't replaced")
+	applicationId, versionCode, versionName = android.MergeVariantOutputVersion(agp8BuildDir, "release", "com.example.override", "", "3.0.0")
+	assert.Equal(t, "com.example.override", applicationId)
+	assert.Equal(t, "7", versionCode)
+	assert.Equal(t, "3.0.0", versionName)
+}
+
+func TestReadAppMetadata(t *testing.T) {
+	t.Log("Testing reading the Android Gradle Plugin version of a variant")
+	appMetadata, err := android.ReadAppMetadata(agp8BuildDir, "release")
+	require.NoError(t, err)
+	assert.Equal(t, "8.1.0", appMetadata["androidGradlePluginVersion"])
+
+	_, err = android.ReadAppMetadata(agp8BuildDir, "debug")
+	assert.Error(t, err)
+}
+
+func TestLibraryModulesAreSkipped(t *testing.T) {
+	t.Log("Testing that library modules aren't treated as applications")
+	assert.True(t, android.IsApplicationModule(agp8BuildDir))
+	assert.False(t, android.IsApplicationModule("../testdata/android/agp8/library/build"))
+	assert.True(t, android.IsApplicationModule(filepath.Join(flavorsProject, "app", "build")))
+
+	variants, err := android.FindProjectVariants("../testdata/android/agp8", nativeLibsOutput, "out", "", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:release"}, variantNames(variants))
+}
@@ -0,0 +1,71 @@
+package android_testing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dynamicFeaturesProject = "../testdata/android/dynamic-features"
+
+var mappingOutput = filepath.Join("outputs", "mapping")
+
+func TestDynamicFeatureVariants(t *testing.T) {
+	t.Log("Testing that dynamic feature modules don't make the variant ambiguous")
+	_, err := android.FindProjectVariants(dynamicFeaturesProject, mappingOutput, "mapping.txt", "", false)
+	assert.EqualError(t, err, "more than one variant found (app:debug, app:release). Please specify using `--variant` or use `--all-variants` to upload all of them")
+
+	t.Log("Testing that only the app module has a mapping file")
+	variants, err := android.FindProjectVariants(dynamicFeaturesProject, mappingOutput, "mapping.txt", "release", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:release"}, variantNames(variants))
+	assert.Equal(t, "", variants[0].Feature)
+
+	t.Log("Testing finding the dynamic feature modules built for the variant of the app")
+	features, err := android.FindFeatureVariants(dynamicFeaturesProject, "release")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dynamicfeature:release"}, variantNames(features))
+	assert.Equal(t, "dynamicfeature", features[0].Feature)
+
+	t.Log("Testing that dynamic feature modules without the variant of the app aren't found")
+	features, err = android.FindFeatureVariants(dynamicFeaturesProject, "debug")
+	require.NoError(t, err)
+	assert.Empty(t, features)
+}
+
+func TestDynamicFeatureBuildUuids(t *testing.T) {
+	t.Log("Testing that every dex file of a module is used for its build UUID")
+	appDexDirs := android.FindBuildDexFiles(filepath.Join(dynamicFeaturesProject, "app", "build"), "release")
+	require.Len(t, appDexDirs, 1)
+
+	appDexFiles, err := android.GetDexFiles(appDexDirs)
+	require.NoError(t, err)
+	assert.Len(t, appDexFiles, 2)
+
+	appSignature, err := android.GetAppSignatureFromFiles(appDexFiles)
+	require.NoError(t, err)
+	assert.Equal(t, "8e9118bcded2a323a602ec847b0ad92a9451d9e6", fmt.Sprintf("%x", appSignature))
+
+	t.Log("Testing that a dynamic feature module has its own build UUID")
+	featureDexDirs := android.FindBuildDexFiles(filepath.Join(dynamicFeaturesProject, "dynamicfeature", "build"), "release")
+	require.Len(t, featureDexDirs, 1)
+	assert.Equal(t, "a87fc2afc9a9e219e2db4a16c28c3d3ef1b27b18", android.GetDexBuildId(featureDexDirs[0]))
+}
+
+func TestGetAabFeatureModules(t *testing.T) {
+	t.Log("Testing listing the dynamic feature modules of an extracted AAB")
+	aabDir := t.TempDir()
+
+	for _, module := range []string{"base", "camera", "maps"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(aabDir, module, "manifest"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(aabDir, module, "manifest", "AndroidManifest.xml"), []byte{}, 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(aabDir, "BUNDLE-METADATA"), 0755))
+
+	assert.Equal(t, []string{"camera", "maps"}, android.GetAabFeatureModules(aabDir))
+}
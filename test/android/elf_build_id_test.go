@@ -0,0 +1,49 @@
+package android_testing
+
+import (
+	"debug/elf"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetElfInfo(t *testing.T) {
+	t.Log("Testing getting the build ID and ABI of a shared object")
+	elfInfo, err := android.GetElfInfo("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	assert.Equal(t, "b8d65d1778f24cb72e473308e088c4c5dd79eb58", elfInfo.BuildId)
+	assert.Equal(t, "x86_64", elfInfo.Arch)
+
+	t.Log("Testing that the build ID is kept in the extracted symbol file")
+	symbolFile, err := android.ExtractDebugInfo("../testdata/android/native/libtest.so", t.TempDir())
+	require.NoError(t, err)
+	symbolInfo, err := android.GetElfInfo(symbolFile)
+	require.NoError(t, err)
+	assert.Equal(t, elfInfo, symbolInfo)
+
+	t.Log("Testing that files that aren't ELF files are rejected")
+	_, err = android.GetElfInfo("../testdata/android/android-mapping.txt")
+	assert.Error(t, err)
+}
+
+func TestGetElfInfoForEachAbi(t *testing.T) {
+	buildIds := make(map[string]bool)
+	for abi, lib := range abiLibraries(t) {
+		t.Log("Testing getting the build ID of the " + abi + " library")
+		elfInfo, err := android.GetElfInfo(lib)
+		require.NoError(t, err)
+		assert.Equal(t, abi, elfInfo.Arch)
+		assert.Len(t, elfInfo.BuildId, 40)
+		assert.False(t, buildIds[elfInfo.BuildId], "Each ABI should have its own build ID")
+		buildIds[elfInfo.BuildId] = true
+	}
+}
+
+func TestGetElfArch(t *testing.T) {
+	assert.Equal(t, "arm64-v8a", android.GetElfArch(elf.EM_AARCH64))
+	assert.Equal(t, "armeabi-v7a", android.GetElfArch(elf.EM_ARM))
+	assert.Equal(t, "x86", android.GetElfArch(elf.EM_386))
+	assert.Equal(t, "", android.GetElfArch(elf.EM_MIPS))
+}
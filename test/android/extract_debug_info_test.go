@@ -0,0 +1,147 @@
+package android_testing
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractDebugInfo(t *testing.T) {
+	t.Log("Testing extracting zlib compressed debug info from a shared object")
+	outputFile, err := android.ExtractDebugInfo("../testdata/android/native/libtest.so", t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "libtest.so.sym", filepath.Base(outputFile))
+
+	original, err := elf.Open("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	defer original.Close()
+
+	extracted, err := elf.Open(outputFile)
+	require.NoError(t, err)
+	defer extracted.Close()
+
+	require.Len(t, extracted.Sections, len(original.Sections))
+	assert.Equal(t, len(original.Progs), len(extracted.Progs))
+
+	for i, section := range extracted.Sections {
+		originalSection := original.Sections[i]
+		assert.Equal(t, originalSection.Name, section.Name)
+		assert.Equal(t, originalSection.Addr, section.Addr, section.Name)
+		assert.Equal(t, originalSection.Size, section.Size, section.Name)
+
+		switch {
+		case strings.HasPrefix(section.Name, ".debug"):
+			assert.NotZero(t, section.Flags&elf.SHF_COMPRESSED, section.Name+" should be compressed")
+		case section.Name == ".text" || section.Name == ".dynsym" || section.Name == ".data":
+			assert.Equal(t, elf.SHT_NOBITS, section.Type, section.Name+" should be removed")
+		case section.Type == elf.SHT_NOTE || section.Name == ".symtab":
+			originalData, err := originalSection.Data()
+			require.NoError(t, err)
+			data, err := section.Data()
+			require.NoError(t, err)
+			assert.Equal(t, originalData, data, section.Name+" should be kept")
+		}
+	}
+
+	extractedDwarf, err := extracted.DWARF()
+	require.NoError(t, err)
+
+	var functions []string
+	reader := extractedDwarf.Reader()
+	for {
+		entry, err := reader.Next()
+		require.NoError(t, err)
+		if entry == nil {
+			break
+		}
+		if name, ok := entry.Val(dwarf.AttrName).(string); ok && entry.Tag == dwarf.TagSubprogram {
+			functions = append(functions, name)
+		}
+	}
+	assert.Subset(t, functions, []string{"bugsnag_test_add", "bugsnag_test_len", "helper"})
+
+	symbols, err := extracted.Symbols()
+	require.NoError(t, err)
+	originalSymbols, err := original.Symbols()
+	require.NoError(t, err)
+	assert.Equal(t, originalSymbols, symbols)
+
+	fileInfo, err := os.Stat(outputFile)
+	require.NoError(t, err)
+	assert.Less(t, fileInfo.Size(), int64(16736))
+}
+
+// abiLibraries - Writes a copy of libtest.so for each 64-bit ABI to lib/<abi>/libtest.so in a temporary directory, with
+// the machine and build ID of each changed so that they can be told apart, and returns the paths by ABI
+func abiLibraries(t *testing.T) map[string]string {
+	library, err := os.ReadFile("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+
+	original, err := elf.Open("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	buildIdNote := original.Section(".note.gnu.build-id")
+	require.NotNil(t, buildIdNote)
+	original.Close()
+
+	machines := map[string]elf.Machine{"arm64-v8a": elf.EM_AARCH64, "x86_64": elf.EM_X86_64}
+	libs := make(map[string]string)
+	dir := t.TempDir()
+	i := 0
+
+	for abi, machine := range machines {
+		i++
+		lib := append([]byte(nil), library...)
+		// e_machine follows the 16 byte identifier and 2 byte type, and the build ID follows the note's header and name
+		binary.LittleEndian.PutUint16(lib[18:], uint16(machine))
+		lib[buildIdNote.Offset+16] = byte(i)
+
+		path := filepath.Join(dir, "lib", abi, "libtest.so")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, lib, 0644))
+		libs[abi] = path
+	}
+
+	return libs
+}
+
+func TestExtractDebugInfoForEachAbi(t *testing.T) {
+	outputDir := t.TempDir()
+	outputFiles := make(map[string]bool)
+
+	for abi, lib := range abiLibraries(t) {
+		t.Log("Testing extracting debug info from the " + abi + " library to its own file")
+		outputFile, err := android.ExtractDebugInfo(lib, outputDir)
+		require.NoError(t, err)
+		assert.Equal(t, "libtest.so.sym", filepath.Base(outputFile))
+		assert.False(t, outputFiles[outputFile], "Each ABI should be extracted to its own file")
+		outputFiles[outputFile] = true
+
+		extracted, err := elf.Open(outputFile)
+		require.NoError(t, err)
+
+		buildId := extracted.Section(".note.gnu.build-id")
+		require.NotNil(t, buildId, "Build ID notes should be kept")
+		assert.Equal(t, elf.SHT_NOTE, buildId.Type)
+		assert.Equal(t, elf.SHT_NOBITS, extracted.Section(".text").Type)
+		extracted.Close()
+	}
+
+	t.Log("Testing that extracting a file of the same name from the same directory doesn't overwrite the first")
+	outputFile, err := android.ExtractDebugInfo("../testdata/android/native/libtest.so", outputDir)
+	require.NoError(t, err)
+	secondOutputFile, err := android.ExtractDebugInfo("../testdata/android/native/libtest.so", outputDir)
+	require.NoError(t, err)
+	assert.NotEqual(t, outputFile, secondOutputFile)
+
+	t.Log("Testing that files that aren't ELF files are rejected")
+	_, err = android.ExtractDebugInfo("../testdata/android/android-mapping.txt", t.TempDir())
+	assert.Error(t, err)
+}
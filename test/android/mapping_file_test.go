@@ -0,0 +1,119 @@
+package android_testing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const r8Mapping = `# compiler: R8
+# compiler_version: 8.1.56
+# min_api: 24
+# common_typos_disable
+# {"id":"com.android.tools.r8.mapping","version":"2.2"}
+# pg_map_id: 5b46e4f
+# pg_map_hash: SHA-256 5b46e4f1a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c
+com.example.MainActivity -> com.example.MainActivity:
+# {"id":"sourceFile","fileName":"MainActivity.kt"}
+    android.widget.TextView label -> a
+    1:4:void onCreate(android.os.Bundle):12:15 -> onCreate
+com.example.Repository -> a.a:
+    java.util.List items -> a
+`
+
+func parseMapping(t *testing.T, content string) *android.MappingFileInfo {
+	info, err := android.ParseMappingFile(strings.NewReader(content))
+	require.NoError(t, err)
+	return info
+}
+
+func TestReadR8MappingHeader(t *testing.T) {
+	t.Log("Testing reading the header of an R8 mapping file")
+	info := parseMapping(t, r8Mapping)
+	assert.Equal(t, "R8", info.Compiler)
+	assert.Equal(t, "8.1.56", info.CompilerVersion)
+	assert.Equal(t, "24", info.MinApi)
+	assert.Equal(t, "5b46e4f", info.MapId)
+	assert.True(t, strings.HasPrefix(info.MapHash, "SHA-256 5b46e4f1"))
+	assert.Equal(t, 2, info.Classes)
+	assert.NoError(t, info.Validate())
+	assert.Equal(t, "", info.InvalidLineWarning())
+
+	t.Log("Testing reading a mapping file without a header")
+	info = parseMapping(t, "com.example.Foo -> a:\n    int bar -> a\n")
+	assert.Equal(t, "", info.MapId)
+	assert.Equal(t, 1, info.Classes)
+	assert.NoError(t, info.Validate())
+
+	t.Log("Testing that an R8 mapping file that doesn't rename any classes is valid")
+	info = parseMapping(t, "# compiler: R8\n# pg_map_id: e3b0c44\n")
+	assert.Equal(t, 0, info.Classes)
+	assert.NoError(t, info.Validate())
+}
+
+func TestReadGzippedMappingFile(t *testing.T) {
+	t.Log("Testing reading a gzipped mapping file")
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(r8Mapping))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	path := filepath.Join(t.TempDir(), "mapping.txt.gz")
+	require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0644))
+
+	info, err := android.ReadMappingFile(path)
+	require.NoError(t, err)
+	assert.True(t, info.Compressed)
+	assert.Equal(t, "5b46e4f", info.MapId)
+	assert.NoError(t, info.Validate())
+
+	t.Log("Testing that a truncated gzipped mapping file is rejected")
+	truncatedPath := filepath.Join(t.TempDir(), "mapping.txt.gz")
+	require.NoError(t, os.WriteFile(truncatedPath, compressed.Bytes()[:compressed.Len()-12], 0644))
+
+	info, err = android.ReadMappingFile(truncatedPath)
+	require.NoError(t, err)
+	assert.EqualError(t, info.Validate(), "the mapping file is truncated")
+}
+
+func TestValidateMappingFiles(t *testing.T) {
+	t.Log("Testing that an empty file is rejected")
+	assert.EqualError(t, parseMapping(t, "").Validate(), "the mapping file is empty")
+
+	t.Log("Testing that a file cut off part way through a line is rejected")
+	truncated := r8Mapping[:len(r8Mapping)-len(" -> a\n")]
+	assert.EqualError(t, parseMapping(t, truncated).Validate(), "the mapping file is truncated")
+
+	t.Log("Testing that a file that isn't a mapping file is rejected")
+	err := parseMapping(t, "<?xml version=\"1.0\"?>\n<plist/>\n").Validate()
+	assert.EqualError(t, err, `this doesn't look like a ProGuard/R8 mapping file, line 1 isn't a valid mapping: "<?xml version=\"1.0\"?>"`)
+
+	t.Log("Testing that members before any class are rejected")
+	assert.Error(t, parseMapping(t, "    int bar -> a\ncom.example.Foo -> a:\n").Validate())
+
+	t.Log("Testing that a file with only comments is rejected")
+	assert.EqualError(t, parseMapping(t, "# nothing to see here\n").Validate(), "the mapping file doesn't map any classes")
+
+	t.Log("Testing that an unexpected line after the first class is only a warning")
+	info := parseMapping(t, "com.example.Foo -> a:\n    int bar -> a\nsomething unexpected\ncom.example.Bar -> b:\n")
+	assert.NoError(t, info.Validate())
+	assert.Equal(t, `line 3 isn't a valid mapping: "something unexpected"`, info.InvalidLineWarning())
+	assert.Equal(t, 2, info.Classes)
+}
+
+func TestReadMappingFileFixture(t *testing.T) {
+	t.Log("Testing reading the mapping file fixture")
+	info, err := android.ReadMappingFile("../testdata/android/android-mapping.txt")
+	require.NoError(t, err)
+	assert.False(t, info.Compressed)
+	assert.Equal(t, 1, info.Classes)
+	assert.NoError(t, info.Validate())
+}
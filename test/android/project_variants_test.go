@@ -0,0 +1,107 @@
+package android_testing
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const flavorsProject = "../testdata/android/flavors"
+
+var nativeLibsOutput = filepath.Join("intermediates", "merged_native_libs")
+
+func variantNames(variants []android.AndroidVariant) []string {
+	var names []string
+	for _, variant := range variants {
+		names = append(names, variant.String())
+	}
+	return names
+}
+
+func TestFindModules(t *testing.T) {
+	t.Log("Testing finding the application modules of a project")
+	modules, err := android.FindModules(flavorsProject, nativeLibsOutput)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "wear"}, modules)
+}
+
+func TestGetVariants(t *testing.T) {
+	t.Log("Testing listing flavored variants")
+	variants, err := android.GetVariants(filepath.Join(flavorsProject, "app", "build", "outputs", "mapping"), "mapping.txt")
+	require.NoError(t, err)
+	require.Len(t, variants, 2)
+	assert.Equal(t, "freeRelease", variants[0].Name)
+	assert.Equal(t, "paidRelease", variants[1].Name)
+
+	t.Log("Testing listing flavored variants nested as <flavor>/<buildType>")
+	variants, err = android.GetVariants("../testdata/android/variants-nested", "mapping.txt")
+	require.NoError(t, err)
+	require.Len(t, variants, 2)
+	assert.Equal(t, "freeRelease", variants[0].Name)
+	assert.Equal(t, filepath.Join("../testdata/android/variants-nested", "free", "release"), variants[0].Path)
+	assert.Equal(t, "paidRelease", variants[1].Name)
+}
+
+func TestFindProjectVariants(t *testing.T) {
+	t.Log("Testing that more than one variant is an error without --variant or --all-variants")
+	_, err := android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "", false)
+	assert.ErrorContains(t, err, "app:freeRelease, app:paidRelease, wear:release")
+	assert.ErrorContains(t, err, "--all-variants")
+
+	t.Log("Testing selecting every variant of every module with --all-variants")
+	variants, err := android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "", true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:freeRelease", "app:paidRelease", "wear:release"}, variantNames(variants))
+	assert.Equal(t, filepath.Join(flavorsProject, "wear", "build"), variants[2].BuildDir)
+
+	t.Log("Testing selecting a variant by its full name")
+	variants, err = android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "paidRelease", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:paidRelease"}, variantNames(variants))
+
+	t.Log("Testing selecting a variant qualified by its module")
+	variants, err = android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "wear:release", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wear:release"}, variantNames(variants))
+
+	t.Log("Testing that a build type matching more than one flavor is an error")
+	_, err = android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "release", false)
+	assert.ErrorContains(t, err, "more than one variant matches release")
+
+	t.Log("Testing that an unknown variant is an error")
+	_, err = android.FindProjectVariants(flavorsProject, nativeLibsOutput, "out", "debug", false)
+	assert.ErrorContains(t, err, "unable to find the debug variant")
+
+	t.Log("Testing that a project without the output is an error")
+	_, err = android.FindProjectVariants("../testdata/android/variants", nativeLibsOutput, "out", "", false)
+	assert.Error(t, err)
+}
+
+func TestSelectVariantsByBuildType(t *testing.T) {
+	t.Log("Testing that a build type selects the only flavored variant of each module")
+	variants := []android.AndroidVariant{
+		{Module: "app", Name: "freeDebug"},
+		{Module: "app", Name: "freeRelease"},
+		{Module: "wear", Name: "release"},
+	}
+
+	selected, err := android.SelectVariants(variants, "release", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app:freeRelease", "wear:release"}, variantNames(selected))
+}
+
+func TestFindVariantManifest(t *testing.T) {
+	t.Log("Testing finding the merged manifest of a variant")
+	manifestPath := android.FindVariantManifest(filepath.Join(flavorsProject, "app", "build"), "paidRelease")
+	assert.Equal(t, filepath.Join(flavorsProject, "app", "build", "intermediates", "merged_manifests", "paidRelease", "AndroidManifest.xml"), manifestPath)
+
+	manifest, err := android.ParseAndroidManifestXML(manifestPath)
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.flavors.paid", manifest.ApplicationId)
+	assert.Equal(t, "20", manifest.VersionCode)
+
+	assert.Equal(t, "", android.FindVariantManifest(filepath.Join(flavorsProject, "app", "build"), "debug"))
+}
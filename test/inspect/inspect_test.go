@@ -0,0 +1,127 @@
+package inspect_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/inspect"
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+)
+
+func TestInspectElfFiles(t *testing.T) {
+	t.Log("Testing inspecting an NDK shared object")
+	info, err := inspect.InspectFile("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeNdk, info.Type)
+	assert.Equal(t, "b8d65d1778f24cb72e473308e088c4c5dd79eb58", info.Metadata["buildId"])
+	assert.Equal(t, "x86_64", info.Metadata["arch"])
+	assert.Equal(t, "yes", info.Metadata["debugInfo"])
+	assert.Empty(t, info.Warnings)
+
+	t.Log("Testing inspecting a Dart symbol file")
+	info, err = inspect.InspectFile("../../features/dart/fixtures/app-debug-info/app.android-arm64.symbols")
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeDart, info.Type)
+	assert.Equal(t, "07cc131ca803c124e93268ce19322737", info.Metadata["buildId"])
+	assert.Equal(t, "android", info.Metadata["platform"])
+	assert.Equal(t, "arm64", info.Metadata["arch"])
+}
+
+func TestInspectDsyms(t *testing.T) {
+	t.Log("Testing inspecting a zip of dSYMs")
+	info, err := inspect.InspectFile("../testdata/ios/dsym-test-fixtures/app.dSYM.zip")
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeDsym, info.Type)
+	require.Len(t, info.Objects, 1)
+	assert.Equal(t, map[string]string{"name": "app", "arch": "x86_64", "uuid": "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", "debugInfo": "yes"}, info.Objects[0])
+
+	t.Log("Testing inspecting the dSYMs in an .xcarchive")
+	fileInfo, err := inspect.InspectPath("../testdata/ios/dsym-test-fixtures/bugsnag-example 14-05-2021,,, 11.27éøœåñü#.xcarchive")
+	require.NoError(t, err)
+	require.Len(t, fileInfo, 1)
+	assert.Len(t, fileInfo[0].Objects, 2)
+}
+
+func TestInspectMappingFiles(t *testing.T) {
+	t.Log("Testing inspecting a ProGuard mapping file")
+	info, err := inspect.InspectFile("../testdata/android/android-mapping.txt")
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeAndroidProguard, info.Type)
+	assert.Equal(t, "1", info.Metadata["classes"])
+
+	t.Log("Testing inspecting a gzipped ProGuard mapping file")
+	mappingPath := filepath.Join(t.TempDir(), "mapping.txt")
+	mapping, err := os.ReadFile("../testdata/android/android-mapping.txt")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(mappingPath, mapping, 0644))
+	compressedMappingPath, err := utils.GzipCompress(mappingPath)
+	require.NoError(t, err)
+	info, err = inspect.InspectFile(compressedMappingPath)
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeAndroidProguard, info.Type)
+	assert.Equal(t, "1", info.Metadata["classes"])
+	assert.Equal(t, "yes", info.Metadata["compressed"])
+
+	t.Log("Testing inspecting the R8 header of a mapping file")
+	r8MappingPath := filepath.Join(t.TempDir(), "mapping.txt")
+	require.NoError(t, os.WriteFile(r8MappingPath, []byte("# compiler: R8\n# compiler_version: 8.1.56\n# pg_map_id: 5b46e4f\ncom.example.Foo -> a:\n    int bar -> a\n"), 0644))
+	info, err = inspect.InspectFile(r8MappingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "R8", info.Metadata["compiler"])
+	assert.Equal(t, "8.1.56", info.Metadata["compilerVersion"])
+	assert.Equal(t, "5b46e4f", info.Metadata["mapId"])
+	assert.Empty(t, info.Warnings)
+
+	t.Log("Testing that inspecting a truncated mapping file warns that it won't be uploaded")
+	require.NoError(t, os.WriteFile(r8MappingPath, []byte("com.example.Foo -> a:\n    int bar -> "), 0644))
+	info, err = inspect.InspectFile(r8MappingPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"This file won't be uploaded as the mapping file is truncated"}, info.Warnings)
+
+	t.Log("Testing inspecting an index source map")
+	sourceMapPath := filepath.Join(t.TempDir(), "index.js.map")
+	require.NoError(t, os.WriteFile(sourceMapPath, []byte(`{
+		"version": 3,
+		"file": "index.js",
+		"sections": [
+			{"offset": {"line": 0, "column": 0}, "map": {"version": 3, "sources": ["a.js", "b.js"], "mappings": "AAAA"}},
+			{"offset": {"line": 10, "column": 0}, "map": {"version": 3, "sources": ["c.js"], "mappings": "AAAA"}}
+		]
+	}`), 0644))
+	info, err = inspect.InspectFile(sourceMapPath)
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeSourceMap, info.Type)
+	assert.Equal(t, "index.js", info.Metadata["file"])
+	assert.Equal(t, "2", info.Metadata["sections"])
+	assert.Equal(t, []string{"a.js", "b.js", "c.js"}, info.Sources)
+	assert.Empty(t, info.Warnings)
+
+	t.Log("Testing inspecting an AAB")
+	info, err = inspect.InspectFile("../../features/android/fixtures/aab/app-release.aab")
+	require.NoError(t, err)
+	assert.Equal(t, inspect.TypeAndroidAab, info.Type)
+	assert.Equal(t, "com.example.picoapp", info.Metadata["applicationId"])
+	assert.Equal(t, "1", info.Metadata["versionCode"])
+	assert.Equal(t, "1.0", info.Metadata["versionName"])
+	assert.Equal(t, "yes", info.Metadata["proguardMapping"])
+}
+
+func TestInspectUnknownFiles(t *testing.T) {
+	t.Log("Testing that files that aren't symbol or mapping files are rejected")
+	_, err := inspect.InspectFile("../testdata/ios/plists/Info.xml.plist")
+	assert.Error(t, err)
+
+	t.Log("Testing that only symbol and mapping files are inspected when searching a directory")
+	fileInfo, err := inspect.InspectPath("../testdata/inspect")
+	require.NoError(t, err)
+
+	var types []string
+	for _, info := range fileInfo {
+		types = append(types, info.Type)
+	}
+	assert.ElementsMatch(t, []string{inspect.TypeAndroidProguard, inspect.TypeSourceMap}, types)
+}
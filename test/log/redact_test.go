@@ -0,0 +1,30 @@
+package log_testing
+
+import (
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	apiKey := "0123456789abcdef0123456789abcdef"
+	log.AddSecret(apiKey)
+	log.AddSecret("")
+
+	t.Log("Testing that registered secrets are masked wherever they appear")
+	assert.Equal(t, "Using ****cdef as API key from AndroidManifest.xml", log.Redact("Using "+apiKey+" as API key from AndroidManifest.xml"))
+	assert.Equal(t, "Using API key from Info.plist: ****cdef", log.Redact("Using API key from Info.plist: "+apiKey))
+
+	t.Log("Testing that API key fields are masked even when the key was not registered")
+	assert.Equal(t, "{\n    \"apiKey\": \"****7890\",\n    \"appVersion\": \"1.0\"\n}", log.Redact("{\n    \"apiKey\": \"abcdefabcdefabcdefabcdef34567890\",\n    \"appVersion\": \"1.0\"\n}"))
+	assert.Equal(t, "api_key=**** other=value", log.Redact("api_key=short other=value"))
+
+	t.Log("Testing that other text is left alone")
+	assert.Equal(t, "Uploading dSYM (UUID 0123ABCD-4567-89EF-0123-456789ABCDEF)", log.Redact("Uploading dSYM (UUID 0123ABCD-4567-89EF-0123-456789ABCDEF)"))
+
+	t.Log("Testing that --show-secrets turns off redaction")
+	log.SetShowSecrets(true)
+	defer log.SetShowSecrets(false)
+	assert.Equal(t, "Using "+apiKey+" as API key", log.Redact("Using "+apiKey+" as API key"))
+}
@@ -0,0 +1,58 @@
+package log_testing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReport struct {
+	Summary map[string]int  `json:"summary"`
+	Files   []log.FileEvent `json:"files"`
+}
+
+func TestWriteReport(t *testing.T) {
+	t.Log("Testing writing a report of every file discovered during a run")
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	log.DiscoverFile("/project/dSYMs/MyApp", map[string]string{"uuid": "E30C1BE5-DEB6-373C-98B4-52D827B7FF0D", "arch": "arm64"})
+	log.DiscoverFile("/project/lib/arm64-v8a/libfoo.so", nil)
+	log.DiscoverFile("/project/lib/arm64-v8a/libbar.so", nil)
+	log.DiscoverFile("/project/mapping.txt", nil)
+	log.AliasFile("/tmp/bugsnag-cli-ndk-1/libfoo.so.sym", "/project/lib/arm64-v8a/libfoo.so")
+
+	log.RecordFile(log.FileEvent{File: "/project/dSYMs/MyApp", UploadType: "dsym", Status: log.StatusFailed, Error: "404 Not Found"})
+	log.RecordFile(log.FileEvent{File: "/project/dSYMs/MyApp", Status: log.StatusUploaded})
+	log.RecordFile(log.FileEvent{File: "/tmp/bugsnag-cli-ndk-1/libfoo.so.sym", UploadType: "ndk-symbol", Status: log.StatusDuplicate, Metadata: map[string]string{"versionCode": "12"}})
+	log.RecordFile(log.FileEvent{File: "/project/mapping.txt", Status: log.StatusSkipped})
+
+	require.NoError(t, log.WriteReport(reportPath))
+
+	data, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var report testReport
+	require.NoError(t, json.Unmarshal(data, &report))
+
+	assert.Equal(t, map[string]int{"files": 4, "uploaded": 1, "duplicate": 1, "dryRun": 0, "failed": 0, "skipped": 1, "notUploaded": 1}, report.Summary)
+	require.Len(t, report.Files, 4)
+
+	assert.Equal(t, "/project/dSYMs/MyApp", report.Files[0].File)
+	assert.Equal(t, log.StatusUploaded, report.Files[0].Status, "The last event should be the outcome")
+	assert.Equal(t, "E30C1BE5-DEB6-373C-98B4-52D827B7FF0D", report.Files[0].BuildId)
+	assert.Equal(t, "arm64", report.Files[0].Metadata["arch"])
+
+	assert.Equal(t, "/project/lib/arm64-v8a/libfoo.so", report.Files[1].File, "Generated files should be reported against the discovered file")
+	assert.Equal(t, log.StatusDuplicate, report.Files[1].Status)
+	assert.Equal(t, "12", report.Files[1].Metadata["versionCode"])
+
+	assert.Equal(t, "/project/lib/arm64-v8a/libbar.so", report.Files[2].File)
+	assert.Equal(t, log.StatusNotUploaded, report.Files[2].Status)
+
+	assert.Equal(t, log.StatusSkipped, report.Files[3].Status)
+}
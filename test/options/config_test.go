@@ -0,0 +1,136 @@
+package options_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/bugsnag/bugsnag-cli/pkg/options"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+api-key: ${TEST_BUGSNAG_API_KEY}
+upload:
+  retries: 2
+  timeout: 60
+  android-ndk:
+    variant: release
+    project-root: app
+    retries: 5
+create-build:
+  release-stage: production
+  metadata:
+    team: mobile
+    attempt: 1
+`
+
+// parse - Parses the arguments using the CLI options and the config resolver
+func parse(t *testing.T, args ...string) (*options.CLI, *options.ConfigResolver) {
+	cli := &options.CLI{}
+	resolver := options.NewConfigResolver()
+
+	parser, err := kong.New(cli, kong.Resolvers(resolver), kong.Vars{"version": "test"})
+	require.NoError(t, err)
+
+	_, err = parser.Parse(args)
+	require.NoError(t, err)
+
+	return cli, resolver
+}
+
+// inDir - Runs the given function with the working directory set to dir
+func inDir(t *testing.T, dir string, run func()) {
+	workingDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer func() { _ = os.Chdir(workingDir) }()
+
+	run()
+}
+
+func TestConfigFile(t *testing.T) {
+	projectDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	nestedDir := filepath.Join(projectDir, "android", "app")
+	require.NoError(t, os.MkdirAll(nestedDir, 0755))
+	configPath := filepath.Join(projectDir, ".bugsnag-cli.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte(testConfig), 0644))
+	t.Setenv("TEST_BUGSNAG_API_KEY", "1234567890ABCDEF1234567890ABCDEF")
+
+	t.Log("Testing that the config file is found from a nested directory and the command sections are applied")
+	inDir(t, nestedDir, func() {
+		cli, resolver := parse(t, "upload", "android-ndk", nestedDir)
+
+		assert.Equal(t, configPath, resolver.Path())
+		assert.Equal(t, "1234567890ABCDEF1234567890ABCDEF", cli.ApiKey, "Environment variables should be expanded")
+		assert.Equal(t, "release", cli.Upload.AndroidNdk.Variant)
+		assert.Equal(t, filepath.Join(projectDir, "app"), cli.Upload.AndroidNdk.ProjectRoot, "Paths should be relative to the config file")
+		assert.Equal(t, 5, cli.Upload.Retries, "The most specific section should win")
+		assert.Equal(t, 60, cli.Upload.Timeout)
+	})
+
+	t.Log("Testing that flags take precedence over the config file")
+	inDir(t, nestedDir, func() {
+		cli, _ := parse(t, "upload", "android-ndk", "--retries=1", "--variant=debug", nestedDir)
+
+		assert.Equal(t, 1, cli.Upload.Retries)
+		assert.Equal(t, "debug", cli.Upload.AndroidNdk.Variant)
+	})
+
+	t.Log("Testing that other commands only use their own sections")
+	inDir(t, nestedDir, func() {
+		cli, _ := parse(t, "create-build", nestedDir)
+
+		assert.Equal(t, "production", cli.CreateBuild.ReleaseStage)
+		assert.Equal(t, map[string]string{"team": "mobile", "attempt": "1"}, cli.CreateBuild.Metadata)
+		assert.Equal(t, 0, cli.CreateBuild.Retries)
+	})
+
+	t.Log("Testing that --config overrides the search")
+	otherConfig := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(otherConfig, []byte("api-key: other\n"), 0644))
+	inDir(t, nestedDir, func() {
+		cli, resolver := parse(t, "--config", otherConfig, "upload", "android-ndk", nestedDir)
+
+		assert.Equal(t, otherConfig, resolver.Path())
+		assert.Equal(t, "other", cli.ApiKey)
+		assert.Equal(t, "", cli.Upload.AndroidNdk.Variant)
+	})
+
+	t.Log("Testing that no config file is used when none is found")
+	inDir(t, t.TempDir(), func() {
+		cli, resolver := parse(t, "upload", "android-ndk", nestedDir)
+
+		assert.Equal(t, "", resolver.Path())
+		assert.Equal(t, "", cli.ApiKey)
+	})
+}
+
+func TestConfigFileEnvPrecedence(t *testing.T) {
+	t.Log("Testing that environment variables bound to a flag take precedence over the config file")
+	var cli struct {
+		ApiKey string `env:"TEST_BUGSNAG_ENV_API_KEY"`
+		Config string `type:"path"`
+	}
+
+	configPath := filepath.Join(t.TempDir(), ".bugsnag-cli.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte("api-key: from-config\n"), 0644))
+
+	parser, err := kong.New(&cli, kong.Resolvers(options.NewConfigResolver()))
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]string{"--config", configPath})
+	require.NoError(t, err)
+	assert.Equal(t, "from-config", cli.ApiKey)
+
+	t.Setenv("TEST_BUGSNAG_ENV_API_KEY", "from-env")
+	parser, err = kong.New(&cli, kong.Resolvers(options.NewConfigResolver()))
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]string{"--config", configPath})
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", cli.ApiKey)
+}
@@ -0,0 +1,45 @@
+package options_testing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironmentVariables(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BUGSNAG_API_KEY", "1234567890ABCDEF1234567890ABCDEF")
+	t.Setenv("BUGSNAG_UPLOAD_API_ROOT_URL", "https://bugsnag.example.com")
+	t.Setenv("BUGSNAG_DRY_RUN", "true")
+	t.Setenv("BUGSNAG_RETRIES", "3")
+	t.Setenv("BUGSNAG_CONCURRENCY", "4")
+
+	t.Log("Testing that global and upload options are read from BUGSNAG_* environment variables")
+	cli, _ := parse(t, "upload", "all", dir)
+
+	assert.Equal(t, "1234567890ABCDEF1234567890ABCDEF", cli.ApiKey)
+	assert.Equal(t, "https://bugsnag.example.com", cli.UploadAPIRootUrl)
+	assert.True(t, cli.DryRun)
+	assert.Equal(t, 3, cli.Upload.Retries)
+	assert.Equal(t, 4, cli.Upload.Concurrency)
+
+	t.Log("Testing that flags take precedence over environment variables")
+	cli, _ = parse(t, "upload", "all", "--retries=1", dir)
+
+	assert.Equal(t, 1, cli.Upload.Retries)
+
+	t.Log("Testing that environment variables take precedence over the config file")
+	configPath := filepath.Join(dir, ".bugsnag-cli.yml")
+	require.NoError(t, os.WriteFile(configPath, []byte("api-key: from-config\nupload:\n  retries: 5\n  timeout: 10\n"), 0644))
+	t.Setenv("BUGSNAG_CONFIG", configPath)
+
+	cli, resolver := parse(t, "upload", "all", dir)
+
+	assert.Equal(t, configPath, resolver.Path())
+	assert.Equal(t, "1234567890ABCDEF1234567890ABCDEF", cli.ApiKey)
+	assert.Equal(t, 3, cli.Upload.Retries)
+	assert.Equal(t, 10, cli.Upload.Timeout)
+}
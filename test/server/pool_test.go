@@ -0,0 +1,63 @@
+package server_testing
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessConcurrently(t *testing.T) {
+	t.Log("Testing that every task is run and the number of workers is bounded")
+	var running int32
+	var maxRunning int32
+	var completed int32
+	var tasks []func() error
+
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, func() error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				previous := atomic.LoadInt32(&maxRunning)
+				if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&completed, 1)
+			return nil
+		})
+	}
+
+	err := server.ProcessConcurrently(3, tasks)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(10), completed, "All tasks should be run")
+	assert.LessOrEqual(t, maxRunning, int32(3), "No more than 3 tasks should run at once")
+
+	t.Log("Testing that errors are aggregated in task order")
+	tasks = []func() error{
+		func() error {
+			time.Sleep(20 * time.Millisecond)
+			return errors.New("first failure")
+		},
+		func() error { return nil },
+		func() error { return errors.New("second failure") },
+	}
+
+	err = server.ProcessConcurrently(3, tasks)
+
+	assert.EqualError(t, err, "2 of 3 uploads failed:\nfirst failure\nsecond failure")
+
+	t.Log("Testing that a single failure is returned as-is")
+	err = server.ProcessConcurrently(2, []func() error{
+		func() error { return nil },
+		func() error { return errors.New("only failure") },
+	})
+
+	assert.EqualError(t, err, "only failure")
+}
@@ -0,0 +1,232 @@
+package server_testing
+
+import (
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/log"
+	"github.com/bugsnag/bugsnag-cli/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessFileRequestStreamsBodyOnRetry(t *testing.T) {
+	t.Log("Testing that a file upload is streamed and re-sent in full when retried")
+	content := []byte("mapping file contents")
+	file := filepath.Join(t.TempDir(), "mapping.txt")
+	require.NoError(t, os.WriteFile(file, content, 0644))
+
+	var mu sync.Mutex
+	var attempts int
+	var receivedFiles []string
+	var receivedFields []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+
+		err := r.ParseMultipartForm(1024)
+		require.NoError(t, err)
+
+		part, _, err := r.FormFile("proguard")
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+
+		receivedFiles = append(receivedFiles, string(data))
+		receivedFields = append(receivedFields, r.FormValue("apiKey"))
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	err := server.ProcessFileRequest(ts.URL, map[string]string{"apiKey": "1234"}, map[string]string{"proguard": file}, 10, 1, 10*time.Millisecond, file, false)
+
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, attempts, "The request should be retried once")
+	assert.Equal(t, []string{string(content), string(content)}, receivedFiles, "The full file should be sent on every attempt")
+	assert.Equal(t, []string{"1234", "1234"}, receivedFields, "The form fields should be sent on every attempt")
+}
+
+func TestProcessFileRequestRetryPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.txt")
+	require.NoError(t, os.WriteFile(file, []byte("mapping file contents"), 0644))
+
+	tt := map[string]struct {
+		statusCode       int
+		retryAfter       string
+		expectedAttempts int32
+	}{
+		"bad requests are not retried": {
+			statusCode:       http.StatusBadRequest,
+			expectedAttempts: 1,
+		},
+		"unauthorized requests are not retried": {
+			statusCode:       http.StatusUnauthorized,
+			expectedAttempts: 1,
+		},
+		"unprocessable requests are not retried": {
+			statusCode:       http.StatusUnprocessableEntity,
+			expectedAttempts: 1,
+		},
+		"throttled requests are retried honouring Retry-After": {
+			statusCode:       http.StatusTooManyRequests,
+			retryAfter:       "1",
+			expectedAttempts: 3,
+		},
+		"unavailable servers are retried": {
+			statusCode:       http.StatusServiceUnavailable,
+			expectedAttempts: 3,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer ts.Close()
+
+			start := time.Now()
+			err := server.ProcessFileRequest(ts.URL, map[string]string{}, map[string]string{"file": file}, 10, 2, 10*time.Millisecond, file, false)
+
+			assert.Error(t, err)
+			assert.Equal(t, tc.expectedAttempts, attempts.Load())
+			assert.Less(t, time.Since(start), time.Second, "Delays should be capped by the maximum retry delay")
+		})
+	}
+}
+
+func TestProcessFileRequestUploadError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "libfoo.so.sym")
+	require.NoError(t, os.WriteFile(file, []byte("symbols"), 0644))
+
+	t.Log("Testing that duplicate files are skipped rather than treated as failures")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer ts.Close()
+
+	err := server.ProcessFileRequest(ts.URL, map[string]string{}, map[string]string{"soFile": file}, 10, 0, 10*time.Millisecond, file, false)
+	assert.NoError(t, err)
+
+	t.Log("Testing that a response body containing status-like digits isn't mistaken for a duplicate")
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte("invalid build 404409"))
+	}))
+	defer ts.Close()
+
+	err = server.ProcessFileRequest(ts.URL+"/ndk-symbol", map[string]string{}, map[string]string{"soFile": file}, 10, 0, 10*time.Millisecond, file, false)
+	require.Error(t, err)
+	assert.False(t, server.IsDuplicate(err))
+	assert.False(t, server.IsNotFound(err))
+
+	var uploadErr *server.UploadError
+	require.ErrorAs(t, err, &uploadErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, uploadErr.StatusCode)
+	assert.Equal(t, "invalid build 404409", uploadErr.ResponseBody)
+	assert.Equal(t, ts.URL+"/ndk-symbol", uploadErr.Endpoint)
+	assert.Equal(t, file, uploadErr.File)
+
+	t.Log("Testing that missing endpoints are reported as not found")
+	ts = httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	err = server.ProcessFileRequest(ts.URL+"/dsym", map[string]string{}, map[string]string{"dsym": file}, 10, 0, 10*time.Millisecond, file, false)
+	assert.True(t, server.IsNotFound(err))
+}
+
+func TestProcessFileRequestRecordsEvent(t *testing.T) {
+	t.Log("Testing that uploads are recorded as file events without credentials")
+	file := filepath.Join(t.TempDir(), "app.android-arm64.symbols")
+	require.NoError(t, os.WriteFile(file, []byte("symbols"), 0644))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"warnings":["this build is old"]}`))
+	}))
+	defer ts.Close()
+
+	uploadOptions := map[string]string{"apiKey": "1234", "buildId": "07cc131ca803c124", "platform": "android"}
+	err := server.ProcessFileRequest(ts.URL+"/dart-symbol", uploadOptions, map[string]string{"symbolFile": file}, 10, 0, 10*time.Millisecond, file, false)
+	require.NoError(t, err)
+
+	events := log.Events()
+	event := events[len(events)-1]
+
+	assert.Equal(t, file, event.File)
+	assert.Equal(t, "dart-symbol", event.UploadType)
+	assert.Equal(t, "07cc131ca803c124", event.BuildId)
+	assert.Equal(t, ts.URL+"/dart-symbol", event.Endpoint)
+	assert.Equal(t, log.StatusUploaded, event.Status)
+	assert.Equal(t, []string{"this build is old"}, event.Warnings)
+	assert.Equal(t, map[string]string{"buildId": "07cc131ca803c124", "platform": "android"}, event.Metadata)
+}
+
+func TestProcessFileRequestNetworkRetryPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.txt")
+	require.NoError(t, os.WriteFile(file, []byte("mapping file contents"), 0644))
+
+	t.Log("Testing that connections closed before a response are retried")
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer ts.Close()
+
+	err := server.ProcessFileRequest(ts.URL, map[string]string{}, map[string]string{"file": file}, 10, 2, 10*time.Millisecond, file, false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+
+	t.Log("Testing that certificates that can't be verified are not retried")
+	var connections atomic.Int32
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	tlsServer.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	tlsServer.Config.ErrorLog = stdlog.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	err = server.ProcessFileRequest(tlsServer.URL, map[string]string{}, map[string]string{"file": file}, 10, 2, 10*time.Millisecond, file, false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), connections.Load())
+
+	t.Log("Testing that malformed URLs are not retried")
+	start := time.Now()
+	err = server.ProcessFileRequest("unknown://upload.example.com", map[string]string{}, map[string]string{"file": file}, 10, 2, time.Second, file, false)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "The request should fail without waiting to retry")
+}
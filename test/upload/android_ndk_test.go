@@ -0,0 +1,133 @@
+package upload_testing
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/android"
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+// writeAbiLibraries - Writes a copy of the test shared object for each 64-bit ABI to lib/<abi>/libtest.so, with the
+// machine and build ID of each changed so that they can be told apart
+func writeAbiLibraries(t *testing.T) []string {
+	library, err := os.ReadFile("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+
+	original, err := elf.Open("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	buildIdNote := original.Section(".note.gnu.build-id")
+	require.NotNil(t, buildIdNote)
+	original.Close()
+
+	var libs []string
+	dir := t.TempDir()
+
+	for i, machine := range []elf.Machine{elf.EM_AARCH64, elf.EM_X86_64} {
+		lib := append([]byte(nil), library...)
+		// e_machine follows the 16 byte identifier and 2 byte type, and the build ID follows the note's header and name
+		binary.LittleEndian.PutUint16(lib[18:], uint16(machine))
+		lib[buildIdNote.Offset+16] = byte(i + 1)
+
+		path := filepath.Join(dir, "lib", android.GetElfArch(machine), "libtest.so")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, lib, 0644))
+		libs = append(libs, path)
+	}
+
+	return libs
+}
+
+func TestProcessAndroidNDKForEachAbi(t *testing.T) {
+	libs := writeAbiLibraries(t)
+	uploadDir := t.TempDir()
+
+	type ndkUpload struct {
+		buildId  string
+		arch     string
+		fileInfo *android.ElfInfo
+	}
+	var uploads []ndkUpload
+	var mutex sync.Mutex
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		part, _, err := r.FormFile("soFile")
+		require.NoError(t, err)
+		defer part.Close()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		// Read the build ID and architecture of the file that was sent, rather than trusting the form fields
+		received := filepath.Join(uploadDir, r.FormValue("arch")+".so.sym")
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(received, content, 0644))
+
+		fileInfo, err := android.GetElfInfo(received)
+		require.NoError(t, err)
+
+		uploads = append(uploads, ndkUpload{r.FormValue("buildId"), r.FormValue("arch"), fileInfo})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Log("Testing that the shared object of each ABI is uploaded with its own build ID and architecture")
+	err := upload.ProcessAndroidNDK("1234567890abcdef1234567890abcdef", "com.example", "", libs, "", "", false, "1", "1.0", "", false, ts.URL, 0, 10, 0, 2, false, false)
+	require.NoError(t, err)
+	require.Len(t, uploads, len(libs))
+
+	expected := make(map[string]string)
+	for _, lib := range libs {
+		libInfo, err := android.GetElfInfo(lib)
+		require.NoError(t, err)
+		expected[libInfo.Arch] = libInfo.BuildId
+	}
+	assert.Len(t, expected, len(libs), "Each ABI should have its own build ID")
+
+	var uploadedArches []string
+	for _, ndkUpload := range uploads {
+		uploadedArches = append(uploadedArches, ndkUpload.arch)
+	}
+	assert.ElementsMatch(t, []string{"arm64-v8a", "x86_64"}, uploadedArches, "Each ABI should be uploaded once")
+
+	for _, ndkUpload := range uploads {
+		assert.Equal(t, expected[ndkUpload.arch], ndkUpload.buildId, "The build ID sent for "+ndkUpload.arch)
+		assert.Equal(t, ndkUpload.arch, ndkUpload.fileInfo.Arch, "The file sent for "+ndkUpload.arch)
+		assert.Equal(t, ndkUpload.buildId, ndkUpload.fileInfo.BuildId, "The file sent for "+ndkUpload.arch)
+	}
+}
+
+func TestProcessAndroidNDKVerifyWithoutBuildId(t *testing.T) {
+	library, err := os.ReadFile("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+
+	original, err := elf.Open("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+	buildIdNote := original.Section(".note.gnu.build-id")
+	require.NotNil(t, buildIdNote)
+	original.Close()
+
+	// Changing the type of the note, which follows its name and description sizes, removes the build ID
+	binary.LittleEndian.PutUint32(library[buildIdNote.Offset+8:], 0)
+	path := filepath.Join(t.TempDir(), "lib", "x86_64", "libtest.so")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, library, 0644))
+
+	t.Log("Testing that a shared object without a build ID doesn't match the binary it is verified against")
+	err = upload.ProcessAndroidNDK("1234567890abcdef1234567890abcdef", "com.example", "", []string{path}, "", "", false, "1", "1.0", "../testdata/android/native/libtest.so", false, "http://localhost", 0, 10, 0, 1, false, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(no UUID or build ID)")
+}
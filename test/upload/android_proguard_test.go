@@ -0,0 +1,45 @@
+package upload_testing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+func TestProcessAndroidProguardForEachFeature(t *testing.T) {
+	var buildUuids []string
+	var mutex sync.Mutex
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		_, _, err := r.FormFile("proguard")
+		require.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.Equal(t, "com.example.features", r.FormValue("appId"))
+		assert.Equal(t, "5", r.FormValue("versionCode"))
+		buildUuids = append(buildUuids, r.FormValue("buildUUID"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	t.Log("Testing that the app's mapping file is uploaded for the build UUID of the app and of each dynamic feature module")
+	err := upload.ProcessAndroidProguard("1234567890abcdef1234567890abcdef", "", "", "", false, nil, []string{"../testdata/android/dynamic-features"}, "release", false, "", "", ts.URL, 0, 10, 0, 2, false, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"8e9118bcded2a323a602ec847b0ad92a9451d9e6", "a87fc2afc9a9e219e2db4a16c28c3d3ef1b27b18"}, buildUuids)
+
+	t.Log("Testing that the mapping file is uploaded once when the build UUID is given")
+	buildUuids = nil
+	err = upload.ProcessAndroidProguard("1234567890abcdef1234567890abcdef", "", "", "my-build-uuid", false, nil, []string{"../testdata/android/dynamic-features"}, "release", false, "", "", ts.URL, 0, 10, 0, 2, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-build-uuid"}, buildUuids)
+}
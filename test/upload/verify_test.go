@@ -0,0 +1,80 @@
+package upload_testing
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/upload"
+)
+
+// writeTestApk - Writes an APK containing the test shared object
+func writeTestApk(t *testing.T) string {
+	library, err := os.ReadFile("../testdata/android/native/libtest.so")
+	require.NoError(t, err)
+
+	apkPath := filepath.Join(t.TempDir(), "app-release.apk")
+	apkFile, err := os.Create(apkPath)
+	require.NoError(t, err)
+
+	writer := zip.NewWriter(apkFile)
+	for name, content := range map[string][]byte{
+		"AndroidManifest.xml":       []byte("not an executable"),
+		"lib/x86_64/libtest.so":     library,
+		"assets/flutter_assets/ABC": {},
+	} {
+		fileWriter, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = fileWriter.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, apkFile.Close())
+
+	return apkPath
+}
+
+func TestGetBinaryIdentifiers(t *testing.T) {
+	t.Log("Testing reading the build IDs of the shared objects in an APK")
+	identifiers, err := upload.GetBinaryIdentifiers(writeTestApk(t))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"b8d65d1778f24cb72e473308e088c4c5dd79eb58": true}, identifiers)
+
+	t.Log("Testing reading the UUIDs of the Mach-O files in a directory")
+	identifiers, err = upload.GetBinaryIdentifiers("../../features/dsym/fixtures/single-dsym")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"3adb330a1c193b98a531d9e09faa3a15": true}, identifiers)
+}
+
+func TestVerifySymbolFiles(t *testing.T) {
+	t.Log("Testing that nothing is verified without a binary")
+	assert.NoError(t, upload.VerifySymbolFiles(map[string]string{"libother.so": "0123"}, "", false))
+
+	t.Log("Testing that matching symbol files are verified")
+	apkPath := writeTestApk(t)
+	assert.NoError(t, upload.VerifySymbolFiles(map[string]string{"libtest.so": "b8d65d1778f24cb72e473308e088c4c5dd79eb58"}, apkPath, false))
+
+	t.Log("Testing that mismatched symbol files fail")
+	err := upload.VerifySymbolFiles(map[string]string{
+		"libtest.so":  "b8d65d1778f24cb72e473308e088c4c5dd79eb58",
+		"libother.so": "0123456789abcdef",
+	}, apkPath, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 symbol file(s) don't match")
+	assert.Contains(t, err.Error(), "libother.so (0123456789abcdef)")
+
+	t.Log("Testing that symbol files without a build ID fail")
+	err = upload.VerifySymbolFiles(map[string]string{"libstripped.so": ""}, apkPath, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "libstripped.so (no UUID or build ID)")
+
+	t.Log("Testing that mismatched symbol files only warn when requested")
+	assert.NoError(t, upload.VerifySymbolFiles(map[string]string{"app (arm64)": "00000000-0000-0000-0000-000000000000"}, "../../features/dsym/fixtures/single-dsym", true))
+
+	t.Log("Testing that dSYM UUIDs are compared regardless of case and dashes")
+	assert.NoError(t, upload.VerifySymbolFiles(map[string]string{"app (x86_64)": "3ADB330A-1C19-3B98-A531-D9E09FAA3A15"}, "../../features/dsym/fixtures/single-dsym", false))
+}
@@ -0,0 +1,145 @@
+package utils_testing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+)
+
+// writeAppStoreConnectKey - Writes a new P-256 private key in the .p8 format used by App Store Connect
+func writeAppStoreConnectKey(t *testing.T) (string, *ecdsa.PrivateKey) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "AuthKey_ABC123.p8")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	return keyPath, privateKey
+}
+
+// verifyAppStoreConnectToken - Checks the signature and claims of an App Store Connect API token
+func verifyAppStoreConnectToken(t *testing.T, authorization string, publicKey *ecdsa.PublicKey) {
+	parts := strings.Split(strings.TrimPrefix(authorization, "Bearer "), ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(publicKey, digest[:], r, s), "The token should be signed with the private key")
+
+	var header, claims map[string]interface{}
+	headerJson, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	claimsJson, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, json.Unmarshal(headerJson, &header))
+	require.NoError(t, json.Unmarshal(claimsJson, &claims))
+
+	assert.Equal(t, map[string]interface{}{"alg": "ES256", "kid": "ABC123", "typ": "JWT"}, header)
+	assert.Equal(t, "issuer-id", claims["iss"])
+	assert.Equal(t, "appstoreconnect-v1", claims["aud"])
+	assert.Less(t, claims["exp"].(float64)-claims["iat"].(float64), float64(20*60))
+}
+
+// Tests downloading dSYMs from a local stub of the App Store Connect API
+func TestAppStoreConnectDownloadDsyms(t *testing.T) {
+	keyPath, privateKey := writeAppStoreConnectKey(t)
+	dsymZip, err := os.ReadFile("../testdata/ios/dsym-test-fixtures/app.dSYM.zip")
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/builds":
+			verifyAppStoreConnectToken(t, r.Header.Get("Authorization"), &privateKey.PublicKey)
+
+			if r.URL.Query().Get("filter[version]") != "42" {
+				_, _ = w.Write([]byte(`{"data":[],"included":[]}`))
+				return
+			}
+
+			assert.Equal(t, "123456789", r.URL.Query().Get("filter[app]"))
+			assert.Equal(t, "buildBundles", r.URL.Query().Get("include"))
+
+			_, _ = w.Write([]byte(`{
+				"data": [{"type": "builds", "id": "build-1"}],
+				"included": [
+					{"type": "buildBundles", "id": "bundle-1", "attributes": {"bundleId": "com.example.app", "dSYMUrl": "` + server.URL + `/dsyms/app.zip"}},
+					{"type": "buildBundles", "id": "bundle-2", "attributes": {"bundleId": "com.example.clip", "dSYMUrl": null}}
+				]
+			}`))
+		case "/dsyms/app.zip":
+			assert.Empty(t, r.Header.Get("Authorization"), "The token should not be sent with the pre-signed download")
+			_, _ = w.Write(dsymZip)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := ios.NewAppStoreConnectClient("ABC123", "issuer-id", keyPath)
+	require.NoError(t, err)
+	client.ApiRootUrl = server.URL
+	client.HttpClient = server.Client()
+
+	t.Log("Testing that the dSYMs of each bundle in the build are downloaded")
+	outputDir := t.TempDir()
+	dsymPaths, err := client.DownloadDsyms("123456789", "42", outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(outputDir, "com.example.app.dSYM.zip")}, dsymPaths)
+
+	dwarfInfo, tempDir, err := ios.FindDsymsInPath(dsymPaths[0], false, false)
+	defer os.RemoveAll(tempDir)
+	require.NoError(t, err)
+	require.Len(t, dwarfInfo, 1)
+	assert.Equal(t, "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", dwarfInfo[0].UUID)
+
+	t.Log("Testing that a missing build is reported")
+	_, err = client.DownloadDsyms("123456789", "43", outputDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unable to find build 43")
+}
+
+// Tests that App Store Connect API errors are reported
+func TestAppStoreConnectErrors(t *testing.T) {
+	keyPath, _ := writeAppStoreConnectKey(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"status":"401","code":"NOT_AUTHORIZED","title":"Authentication credentials are missing or invalid.","detail":"Provide a properly configured and signed bearer token."}]}`))
+	}))
+	defer server.Close()
+
+	client, err := ios.NewAppStoreConnectClient("ABC123", "issuer-id", keyPath)
+	require.NoError(t, err)
+	client.ApiRootUrl = server.URL
+
+	_, err = client.DownloadDsyms("123456789", "42", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+	assert.Contains(t, err.Error(), "Provide a properly configured and signed bearer token.")
+
+	_, err = ios.NewAppStoreConnectClient("ABC123", "issuer-id", "../testdata/ios/plists/Info.xml.plist")
+	assert.Error(t, err, "A file that isn't a private key should be rejected")
+}
@@ -0,0 +1,87 @@
+package utils_testing
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+)
+
+const testAppInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>2.3.4</string>
+	<key>CFBundleVersion</key>
+	<string>56</string>
+	<key>bugsnag</key>
+	<dict>
+		<key>apiKey</key>
+		<string>0123456789abcdef0123456789abcdef</string>
+	</dict>
+</dict>
+</plist>
+`
+
+// Tests reading the dSYMs and app details from an .xcarchive
+func TestReadXcarchive(t *testing.T) {
+	archivePath := "../testdata/ios/dsym-test-fixtures/bugsnag-example 14-05-2021,,, 11.27éøœåñü#.xcarchive"
+
+	assert.True(t, ios.IsXcarchive(archivePath))
+	assert.False(t, ios.IsIpa(archivePath))
+
+	archiveInfo, err := ios.ReadXcarchive(archivePath, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(archivePath, "dSYMs"), archiveInfo.DsymPath)
+	assert.Equal(t, "1.1", archiveInfo.PlistData.VersionName)
+	assert.Equal(t, "1", archiveInfo.PlistData.BundleVersion)
+	assert.Equal(t, "", archiveInfo.TempDir)
+
+	dwarfInfo, _, err := ios.FindDsymsInPath(archiveInfo.DsymPath, false, false)
+	require.NoError(t, err)
+	assert.Len(t, dwarfInfo, 2)
+}
+
+// Tests reading the app details from an .ipa and finding the dSYMs exported alongside it
+func TestReadIpa(t *testing.T) {
+	outputDir := t.TempDir()
+	ipaPath := filepath.Join(outputDir, "Example.ipa")
+
+	ipaFile, err := os.Create(ipaPath)
+	require.NoError(t, err)
+	writer := zip.NewWriter(ipaFile)
+	plistWriter, err := writer.Create("Payload/Example.app/Info.plist")
+	require.NoError(t, err)
+	_, err = plistWriter.Write([]byte(testAppInfoPlist))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, ipaFile.Close())
+
+	assert.True(t, ios.IsIpa(ipaPath))
+
+	t.Log("Testing that an .ipa without dSYMs is reported")
+	archiveInfo, err := ios.ReadIpa(ipaPath, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "No dSYMs found")
+	_ = os.RemoveAll(archiveInfo.TempDir)
+
+	t.Log("Testing that the dSYMs exported alongside an .ipa are used")
+	dsymZip, err := os.ReadFile("../testdata/ios/dsym-test-fixtures/app.dSYM.zip")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "Example.app.dSYM.zip"), dsymZip, 0644))
+
+	archiveInfo, err = ios.ReadIpa(ipaPath, false)
+	require.NoError(t, err)
+	defer os.RemoveAll(archiveInfo.TempDir)
+
+	assert.Equal(t, filepath.Join(outputDir, "Example.app.dSYM.zip"), archiveInfo.DsymPath)
+	assert.Equal(t, "2.3.4", archiveInfo.PlistData.VersionName)
+	assert.Equal(t, "56", archiveInfo.PlistData.BundleVersion)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", archiveInfo.PlistData.BugsnagProjectDetails.ApiKey)
+}
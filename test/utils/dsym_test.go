@@ -157,3 +157,54 @@ func TestGetXcodeBuildSettings(t *testing.T) {
 		})
 	}
 }
+
+// Tests reading UUIDs and architectures from the Mach-O headers of dSYMs, without needing dwarfdump
+func TestGetMachOUuids(t *testing.T) {
+	tt := map[string]struct {
+		path     string
+		expected []ios.DwarfInfo
+	}{
+		"single architecture dSYM": {
+			path:     "../testdata/ios/dsym-test-fixtures/dsyms/app.dSYM/Contents/Resources/DWARF/app",
+			expected: []ios.DwarfInfo{{UUID: "3ADB330A-1C19-3B98-A531-D9E09FAA3A15", Arch: "x86_64"}},
+		},
+		"fat dSYM": {
+			path: "../testdata/ios/dsym-test-fixtures/bugsnag-example 14-05-2021,,, 11.27éøœåñü#.xcarchive/dSYMs/bugsnag-example.app.dSYM/Contents/Resources/DWARF/bugsnag-example",
+			expected: []ios.DwarfInfo{
+				{UUID: "05646553-325F-3328-95F1-CA8B309997D4", Arch: "armv7"},
+				{UUID: "C35D08AB-C227-317A-8B25-8301E60BA6EC", Arch: "arm64"},
+			},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dwarfInfo, err := ios.GetMachOUuids(tc.path)
+			require.NoError(t, err)
+
+			var actual []ios.DwarfInfo
+			for _, dwarf := range dwarfInfo {
+				actual = append(actual, *dwarf)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("not a Mach-O file", func(t *testing.T) {
+		_, err := ios.GetMachOUuids("../testdata/ios/dsym-test-fixtures/bugsnag-example 14-05-2021,,, 11.27éøœåñü#.xcarchive/Info.plist")
+		assert.Error(t, err)
+	})
+}
+
+// Tests finding dSYMs in a directory and reading their UUIDs
+func TestFindDsymsInPath(t *testing.T) {
+	dwarfInfo, _, err := ios.FindDsymsInPath("../testdata/ios/dsym-test-fixtures/single-dsym", false, false)
+	require.NoError(t, err)
+	require.Len(t, dwarfInfo, 1)
+
+	assert.Equal(t, "app", dwarfInfo[0].Name)
+	assert.Equal(t, "../testdata/ios/dsym-test-fixtures/single-dsym/app.dSYM/Contents/Resources/DWARF", dwarfInfo[0].Location)
+	assert.NotEmpty(t, dwarfInfo[0].UUID)
+	assert.NotEmpty(t, dwarfInfo[0].Arch)
+}
@@ -0,0 +1,86 @@
+package utils_testing
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+)
+
+// Tests reading Info.plist files in each format without plutil
+func TestGetPlistData(t *testing.T) {
+	expected := &ios.PlistData{VersionName: "1.2.3", BundleVersion: "42"}
+	expected.BugsnagProjectDetails.ApiKey = "0123456789abcdef0123456789abcdef"
+
+	for _, format := range []string{"xml", "binary", "openstep"} {
+		t.Run(format, func(t *testing.T) {
+			plistData, err := ios.GetPlistData("../testdata/ios/plists/Info."+format+".plist", false)
+			require.NoError(t, err)
+			assert.Equal(t, expected, plistData)
+		})
+	}
+
+	t.Run("invalid plist", func(t *testing.T) {
+		_, err := ios.GetPlistData("../testdata/ios/MyTestApp.zip", false)
+		assert.Error(t, err)
+	})
+}
+
+// Tests decoding every value type from XML and binary plists
+func TestDecodePlist(t *testing.T) {
+	for _, format := range []string{"xml", "binary"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := os.ReadFile("../testdata/ios/plists/Info." + format + ".plist")
+			require.NoError(t, err)
+
+			value, err := ios.DecodePlist(data)
+			require.NoError(t, err)
+
+			plist := value.(map[string]interface{})
+			assert.Equal(t, "com.example.app", plist["CFBundleIdentifier"])
+			assert.Equal(t, map[string]interface{}{"apiKey": "0123456789abcdef0123456789abcdef", "releaseStage": "production"}, plist["bugsnag"])
+			assert.Equal(t, []interface{}{"arm64"}, plist["UIRequiredDeviceCapabilities"])
+			assert.Equal(t, true, plist["LSRequiresIPhoneOS"])
+			assert.Equal(t, int64(1234567890123), plist["BuildNumber"])
+			assert.Equal(t, 1.5, plist["Ratio"])
+			assert.Equal(t, []byte{0, 1, 2}, plist["Icon"])
+			assert.True(t, time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC).Equal(plist["Built"].(time.Time)))
+			assert.Equal(t, "Café ☕", plist["Name"])
+		})
+	}
+
+	t.Run("openstep", func(t *testing.T) {
+		data, err := os.ReadFile("../testdata/ios/plists/Info.openstep.plist")
+		require.NoError(t, err)
+
+		value, err := ios.DecodePlist(data)
+		require.NoError(t, err)
+
+		plist := value.(map[string]interface{})
+		assert.Equal(t, "com.example.app", plist["CFBundleIdentifier"])
+		assert.Equal(t, []interface{}{"arm64"}, plist["UIRequiredDeviceCapabilities"])
+		assert.Equal(t, []byte{0, 1, 2}, plist["Icon"])
+		assert.Equal(t, "Café ☕", plist["Name"])
+		assert.Equal(t, "quote \" tab \t octal A", plist["Escaped"])
+	})
+
+	t.Run("malformed plists", func(t *testing.T) {
+		for _, data := range []string{
+			"bplist00",
+			"bplist00" + string(make([]byte, 32)),
+			"<plist><dict><string>no key</string></dict></plist>",
+			"{ key = value ",
+			"( one two )",
+			// A dictionary with a length far longer than the file, whose size in bytes overflows
+			"bplist00\xdf\x13\x40\x00\x00\x00\x00\x00\x00\x00\x08" + string(make([]byte, 6)) + "\x01\x02" +
+				"\x00\x00\x00\x00\x00\x00\x00\x01" + string(make([]byte, 8)) + "\x00\x00\x00\x00\x00\x00\x00\x12",
+		} {
+			_, err := ios.DecodePlist([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
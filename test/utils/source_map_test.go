@@ -0,0 +1,168 @@
+package utils_testing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// segment - Builds a source map segment, with -1 for a missing source or name
+func segment(generatedLine, generatedColumn, source, originalLine, originalColumn, name int) utils.SourceMapSegment {
+	return utils.SourceMapSegment{
+		GeneratedLine:   generatedLine,
+		GeneratedColumn: generatedColumn,
+		Source:          source,
+		OriginalLine:    originalLine,
+		OriginalColumn:  originalColumn,
+		Name:            name,
+	}
+}
+
+// TestSourceMapMappings - Tests decoding and encoding the mappings of a source map
+func TestSourceMapMappings(t *testing.T) {
+	t.Log("Testing decoding mappings with relative fields, generated-only segments and empty lines")
+	segments, err := utils.DecodeMappings("AAAA,KAAKC,E;;gBCIgB")
+	require.NoError(t, err)
+	assert.Equal(t, []utils.SourceMapSegment{
+		segment(0, 0, 0, 0, 0, -1),
+		segment(0, 5, 0, 0, 5, 1),
+		segment(0, 7, -1, 0, 0, -1),
+		segment(2, 16, 1, 4, 21, -1),
+	}, segments)
+
+	t.Log("Testing encoding the decoded mappings gives the original mappings")
+	assert.Equal(t, "AAAA,KAAKC,E;;gBCIgB", utils.EncodeMappings(segments))
+
+	t.Log("Testing decoding invalid mappings")
+	_, err = utils.DecodeMappings("AA!A")
+	assert.Error(t, err)
+	_, err = utils.DecodeMappings("AAg")
+	assert.Error(t, err)
+	_, err = utils.DecodeMappings("AA")
+	assert.Error(t, err)
+
+	t.Log("Testing decoding mappings that sum to a negative position")
+	for _, mappings := range []string{"AADA", "AAAD", "ADAA", "AAAAD", "D"} {
+		_, err = utils.DecodeMappings(mappings)
+		assert.Error(t, err, mappings)
+	}
+}
+
+// TestComposeSourceMaps - Tests composing the source map of a bundle with the source map of its compiled bytecode
+func TestComposeSourceMaps(t *testing.T) {
+	contentA, contentB := "a()", "b()"
+	packagerMap := &utils.SourceMap{
+		Version:        3,
+		Sources:        []string{"a.js", "b.js", "c.js"},
+		SourcesContent: []*string{&contentA, &contentB, nil},
+		Names:          []string{"foo", "bar"},
+		Mappings: utils.EncodeMappings([]utils.SourceMapSegment{
+			segment(0, 0, 0, 0, 0, 0),
+			segment(0, 10, 1, 4, 2, 1),
+			segment(0, 20, -1, 0, 0, -1),
+			segment(0, 30, 2, 1, 0, -1),
+			segment(1, 0, 1, 7, 0, -1),
+		}),
+		FacebookSources:  []json.RawMessage{json.RawMessage(`[{"names":["<global>"]}]`), nil, nil},
+		GoogleIgnoreList: []int{1, 2},
+	}
+	compilerMap := &utils.SourceMap{
+		Version: 3,
+		Sources: []string{"index.android.bundle"},
+		Names:   []string{},
+		Mappings: utils.EncodeMappings([]utils.SourceMapSegment{
+			segment(0, 0, 0, 0, 5, -1),
+			segment(0, 8, 0, 0, 15, -1),
+			segment(0, 12, 0, 0, 25, -1),
+			segment(0, 16, 0, 1, 3, -1),
+			segment(0, 20, 0, 5, 0, -1),
+		}),
+		HermesFunctionOffsets: json.RawMessage(`{"0":[0,8]}`),
+	}
+
+	t.Log("Testing each position in the bytecode is mapped to the closest position before it in the bundle")
+	composed, err := utils.ComposeSourceMaps(packagerMap, compilerMap)
+	require.NoError(t, err)
+	segments, err := utils.DecodeMappings(composed.Mappings)
+	require.NoError(t, err)
+	assert.Equal(t, []utils.SourceMapSegment{
+		segment(0, 0, 0, 0, 0, 0),
+		segment(0, 8, 1, 4, 2, 1),
+		segment(0, 12, -1, 0, 0, -1),
+		segment(0, 16, 1, 7, 0, -1),
+		segment(0, 20, -1, 0, 0, -1),
+	}, segments)
+
+	t.Log("Testing only the sources mapped to are kept, along with their content and metadata")
+	assert.Equal(t, 3, composed.Version)
+	assert.Equal(t, []string{"a.js", "b.js"}, composed.Sources)
+	assert.Equal(t, []string{"foo", "bar"}, composed.Names)
+	assert.Equal(t, []*string{&contentA, &contentB}, composed.SourcesContent)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`[{"names":["<global>"]}]`), nil}, composed.FacebookSources)
+	assert.Equal(t, []int{1}, composed.GoogleIgnoreList)
+
+	t.Log("Testing the function offsets from the Hermes compiler are kept")
+	assert.JSONEq(t, `{"0":[0,8]}`, string(composed.HermesFunctionOffsets))
+
+	t.Log("Testing mappings to a source or name that the source map doesn't have can't be composed")
+	invalidMap := *packagerMap
+	invalidMap.Sources = []string{"a.js"}
+	_, err = utils.ComposeSourceMaps(&invalidMap, compilerMap)
+	assert.EqualError(t, err, "mapping on line 1 refers to source 1, but the source map has 1 sources")
+
+	invalidMap = *packagerMap
+	invalidMap.Names = []string{"foo"}
+	_, err = utils.ComposeSourceMaps(&invalidMap, compilerMap)
+	assert.EqualError(t, err, "mapping on line 1 refers to name 1, but the source map has 1 names")
+
+	t.Log("Testing a compiled map that maps to a negative line can't be composed")
+	negativeMap := *compilerMap
+	negativeMap.Mappings = "AADA"
+	_, err = utils.ComposeSourceMaps(packagerMap, &negativeMap)
+	assert.Error(t, err)
+
+	t.Log("Testing index source maps can't be composed")
+	packagerMap.Sections = []json.RawMessage{json.RawMessage(`{}`)}
+	_, err = utils.ComposeSourceMaps(packagerMap, compilerMap)
+	assert.Error(t, err)
+}
+
+// TestHermesSourceMaps - Tests finding and composing the source maps of a Hermes bytecode bundle
+func TestHermesSourceMaps(t *testing.T) {
+	t.Log("Testing detecting a bundle compiled to Hermes bytecode")
+	assert.True(t, utils.IsHermesBytecode("../../features/react-native-android/fixtures/rn0_72/android/app/build/generated/assets/createBundleReleaseJsAndAssets/index.android.bundle"))
+	assert.False(t, utils.IsHermesBytecode("../../README.md"))
+	assert.False(t, utils.IsHermesBytecode("../testdata/missing.bundle"))
+
+	t.Log("Testing detecting a source map composed with the Hermes compiler's")
+	assert.True(t, utils.IsHermesSourceMap("../../features/react-native-android/fixtures/rn0_72/android/app/build/generated/sourcemaps/react/release/index.android.bundle.map"))
+
+	dir := t.TempDir()
+	intermediatesDir := filepath.Join(dir, "intermediates")
+	require.NoError(t, os.MkdirAll(intermediatesDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(intermediatesDir, "index.android.bundle.packager.map"),
+		[]byte(`{"version":3,"sources":["App.js"],"names":["render"],"mappings":"AAAA,IAAKA"}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.android.bundle.compiler.map"),
+		[]byte(`{"version":3,"sources":["index.android.bundle"],"names":[],"mappings":"AAAA,UAAM","x_hermes_function_offsets":{"0":[0]}}`), 0644))
+
+	t.Log("Testing finding the packager and compiler source maps in different directories")
+	packagerMapPath, compilerMapPath := utils.FindHermesSourceMaps("/build/index.android.bundle", []string{"", dir, intermediatesDir})
+	assert.Equal(t, filepath.Join(intermediatesDir, "index.android.bundle.packager.map"), packagerMapPath)
+	assert.Equal(t, filepath.Join(dir, "index.android.bundle.compiler.map"), compilerMapPath)
+	assert.False(t, utils.IsHermesSourceMap(packagerMapPath))
+
+	t.Log("Testing composing the source maps into a file")
+	composedMapPath := filepath.Join(dir, "index.android.bundle.map")
+	require.NoError(t, utils.ComposeHermesSourceMap(packagerMapPath, compilerMapPath, composedMapPath))
+	composed, err := utils.ReadSourceMap(composedMapPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"App.js"}, composed.Sources)
+	assert.Equal(t, []string{"render"}, composed.Names)
+	assert.Equal(t, "AAAA,UAAKA", composed.Mappings)
+	assert.True(t, utils.IsHermesSourceMap(composedMapPath))
+}
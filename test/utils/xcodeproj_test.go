@@ -0,0 +1,202 @@
+package utils_testing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bugsnag/bugsnag-cli/pkg/ios"
+)
+
+const testPbxproj = `// !$*UTF8*$!
+{
+	archiveVersion = 1;
+	objects = {
+		A1 /* Project object */ = {
+			isa = PBXProject;
+			buildConfigurationList = A2;
+			mainGroup = A10;
+			targets = ( A20 );
+		};
+		A2 = {
+			isa = XCConfigurationList;
+			buildConfigurations = ( A3, A4 );
+			defaultConfigurationName = Release;
+		};
+		A3 = { isa = XCBuildConfiguration; name = Debug; buildSettings = { SDKROOT = iphoneos; }; };
+		A4 = {
+			isa = XCBuildConfiguration;
+			name = Release;
+			baseConfigurationReference = A11 /* Shared.xcconfig */;
+			buildSettings = {
+				SDKROOT = iphoneos;
+				OTHER_FLAGS = "$(inherited) -project";
+			};
+		};
+		A10 = { isa = PBXGroup; children = ( A12 ); sourceTree = "<group>"; };
+		A12 = { isa = PBXGroup; children = ( A11 ); path = Config; sourceTree = "<group>"; };
+		A11 = { isa = PBXFileReference; path = Shared.xcconfig; sourceTree = "<group>"; };
+		A20 = {
+			isa = PBXNativeTarget;
+			name = "My App";
+			productType = "com.apple.product-type.application";
+			buildConfigurationList = A21;
+		};
+		A21 = { isa = XCConfigurationList; buildConfigurations = ( A22 ); };
+		A22 = {
+			isa = XCBuildConfiguration;
+			name = Release;
+			buildSettings = {
+				INFOPLIST_FILE = "$(TARGET_NAME:rfc1034identifier)/Info.plist";
+				PRODUCT_NAME = "${TARGET_NAME:c99extidentifier}";
+				"OTHER_FLAGS[sdk=iphonesimulator*]" = "-simulator";
+				OTHER_FLAGS = ( "$(inherited)", "-target" );
+			};
+		};
+	};
+	rootObject = A1 /* Project object */;
+}
+`
+
+const testXcconfig = `// Shared settings
+#include "Base.xcconfig"
+OTHER_FLAGS = $(inherited) -xcconfig // trailing comment
+`
+
+// Tests resolving build settings natively from project.pbxproj and xcconfig files
+func TestXcodeProjectBuildSettings(t *testing.T) {
+	projectDir := t.TempDir()
+	projectPath := filepath.Join(projectDir, "MyApp.xcodeproj")
+	require.NoError(t, os.MkdirAll(projectPath, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "project.pbxproj"), []byte(testPbxproj), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Config", "Shared.xcconfig"), []byte(testXcconfig), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Config", "Base.xcconfig"), []byte("OTHER_FLAGS = -base\n"), 0644))
+
+	project, err := ios.LoadXcodeProject(projectPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"My App"}, project.Targets())
+	assert.Equal(t, "Release", project.DefaultConfiguration())
+
+	settings, err := project.BuildSettings("My App", "", projectPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Release", settings["CONFIGURATION"])
+	assert.Equal(t, "My_App", settings["PRODUCT_NAME"])
+	assert.Equal(t, "My_App.app", settings["FULL_PRODUCT_NAME"])
+	assert.Equal(t, "My_App.app.dSYM", settings["DWARF_DSYM_FILE_NAME"])
+	assert.Equal(t, "My_App.app/Info.plist", settings["INFOPLIST_PATH"])
+	assert.Equal(t, "My-App/Info.plist", settings["INFOPLIST_FILE"])
+	assert.Equal(t, "-base -xcconfig -project -target", settings["OTHER_FLAGS"])
+	assert.Contains(t, settings["CONFIGURATION_BUILD_DIR"], filepath.Join("DerivedData", "MyApp-"))
+	assert.Contains(t, settings["CONFIGURATION_BUILD_DIR"], filepath.Join("Build", "Products", "Release-iphoneos"))
+	assert.Equal(t, settings["CONFIGURATION_BUILD_DIR"], settings["BUILT_PRODUCTS_DIR"])
+
+	_, err = project.BuildSettings("Missing", "", projectPath)
+	assert.Error(t, err)
+}
+
+// Tests listing schemes natively from workspaces, projects and scheme files
+func TestFindXcodeSchemes(t *testing.T) {
+	tt := map[string]struct {
+		path            string
+		expectedSchemes []string
+	}{
+		"shared scheme in a workspace": {
+			path:            "../testdata/ios/WorkspaceExample.xcworkspace",
+			expectedSchemes: []string{"WorkspaceScheme"},
+		},
+		"schemes recorded by Xcode for a project": {
+			path:            "../testdata/ios/SingleSchemeExample",
+			expectedSchemes: []string{"SingleSchemeExample"},
+		},
+		"schemes autocreated for each target": {
+			path:            "../testdata/ios/MultipleSchemeExample/MultipleSchemeExample.xcodeproj",
+			expectedSchemes: []string{"MultipleSchemeExample", "MultipleSchemeExampleTests", "MultipleSchemeExampleUITests"},
+		},
+		"shared scheme in a project referenced by a workspace": {
+			path:            "../../features/base-fixtures/rn0_69/ios/rn0_69.xcworkspace",
+			expectedSchemes: []string{"rn0_69"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			schemes, err := ios.FindXcodeSchemes(tc.path)
+			require.NoError(t, err)
+
+			var schemeNames []string
+			for _, scheme := range schemes {
+				schemeNames = append(schemeNames, scheme.Name)
+			}
+
+			assert.Equal(t, tc.expectedSchemes, schemeNames)
+		})
+	}
+
+	t.Run("scheme builds the target it runs", func(t *testing.T) {
+		schemes, err := ios.FindXcodeSchemes("../../features/base-fixtures/rn0_72/ios")
+		require.NoError(t, err)
+		require.Len(t, schemes, 1)
+
+		projectPath, target, err := schemes[0].BuildTarget()
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("..", "..", "features", "base-fixtures", "rn0_72", "ios", "rn0_72.xcodeproj"), projectPath)
+		assert.Equal(t, "rn0_72", target)
+	})
+}
+
+const testXcscheme = `<?xml version="1.0" encoding="UTF-8"?>
+<Scheme version = "1.7">
+   <BuildAction>
+      <BuildActionEntries>
+         <BuildActionEntry buildForArchiving = "YES">
+            <BuildableReference BlueprintName = "My App" ReferencedContainer = "container:MyApp.xcodeproj">
+            </BuildableReference>
+         </BuildActionEntry>
+      </BuildActionEntries>
+   </BuildAction>
+   <LaunchAction buildConfiguration = "Debug">
+      <BuildableProductRunnable>
+         <BuildableReference BlueprintName = "My App" ReferencedContainer = "container:MyApp.xcodeproj">
+         </BuildableReference>
+      </BuildableProductRunnable>
+   </LaunchAction>
+   <ArchiveAction buildConfiguration = "Release">
+   </ArchiveAction>
+</Scheme>
+`
+
+// Tests resolving the build settings of a scheme natively with the configuration xcodebuild uses, which is the
+// project's default rather than the one the scheme launches with
+func TestXcodeSchemeBuildSettings(t *testing.T) {
+	projectDir := t.TempDir()
+	projectPath := filepath.Join(projectDir, "MyApp.xcodeproj")
+	schemesPath := filepath.Join(projectPath, "xcshareddata", "xcschemes")
+	require.NoError(t, os.MkdirAll(schemesPath, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "Config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "project.pbxproj"), []byte(testPbxproj), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(schemesPath, "My App.xcscheme"), []byte(testXcscheme), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Config", "Shared.xcconfig"), []byte(testXcconfig), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "Config", "Base.xcconfig"), []byte("OTHER_FLAGS = -base\n"), 0644))
+
+	schemes, err := ios.FindXcodeSchemes(projectPath)
+	require.NoError(t, err)
+	require.Len(t, schemes, 1)
+
+	buildSettings, err := ios.GetXcodeBuildSettings(projectPath, "My App")
+	require.NoError(t, err)
+	assert.Equal(t, "Release", buildSettings.Configuration)
+	assert.Contains(t, buildSettings.ConfigurationBuildDir, filepath.Join("Build", "Products", "Release-iphoneos"))
+
+	t.Log("Testing Release is used when the project has no default configuration")
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "project.pbxproj"), []byte(strings.Replace(testPbxproj, "defaultConfigurationName = Release;", "", 1)), 0644))
+	buildSettings, err = ios.GetXcodeBuildSettings(projectPath, "My App")
+	require.NoError(t, err)
+	assert.Equal(t, "Release", buildSettings.Configuration)
+}